@@ -3,18 +3,14 @@ package async
 import (
 	"context"
 	"errors"
-	"fmt"
-	"log/slog"
-	"runtime/debug"
 	"sync"
 )
 
 type contextKey string
 
 var (
-	contextKeyWG         contextKey = "wg"
-	contextKeyCancel     contextKey = "cancel"
-	contextKeyErrorsChan contextKey = "errorsCh"
+	contextKeyScope    contextKey = "scope"
+	contextKeyCapacity contextKey = "capacity"
 )
 
 var ErrChannelClosed = errors.New("channel is closed")
@@ -22,10 +18,15 @@ var ErrChannelClosed = errors.New("channel is closed")
 // Func is a channel writer callback.
 type Func[T any] func(chan<- Option[T]) error
 
+// FuncCtx is a channel writer callback receiving the context of the task.
+type FuncCtx[T any] func(ctx context.Context, ch chan<- Option[T]) error
+
 // Option is a wrapped pair of value and error.
 type Option[T any] struct {
-	value T
-	err   error
+	value   T
+	err     error
+	partial bool
+	final   bool
 }
 
 // Value unwraps opt's value.
@@ -34,6 +35,28 @@ func (opt Option[T]) Value() T { return opt.value }
 // Value unwraps opt's error.
 func (opt Option[T]) Err() error { return opt.err }
 
+// Unwrap unwraps opt to value and error.
+func (opt Option[T]) Unwrap() (T, error) { return opt.value, opt.err }
+
+// IsPartial reports whether opt holds value along with error, see MakeValueErr.
+func (opt Option[T]) IsPartial() bool { return opt.partial }
+
+// IsFinal reports whether opt holds the error returned by the task function or its recovered panic,
+// so the task has failed and opt is the last option of the channel, unlike the errors sent by the function itself.
+func (opt Option[T]) IsFinal() bool { return opt.final }
+
+// IsErr reports whether opt holds error.
+func (opt Option[T]) IsErr() bool { return opt.err != nil }
+
+// Must unwraps opt's value, it panics if opt holds error.
+func (opt Option[T]) Must() T {
+	if opt.err != nil {
+		panic(opt.err)
+	}
+
+	return opt.value
+}
+
 // MakeValue wraps value.
 func MakeValue[T any](v T) Option[T] {
 	return Option[T]{value: v}
@@ -44,84 +67,329 @@ func MakeErr[T any](err error) Option[T] {
 	return Option[T]{err: err}
 }
 
+// MakeValueErr wraps partial value together with non-fatal error, e.g. the result of truncated read.
+// Await returns both of them, Collect and CollectAll keep the partial value.
+func MakeValueErr[T any](v T, err error) Option[T] {
+	return Option[T]{value: v, err: err, partial: err != nil}
+}
+
 // Go safely runs function f at a new goroutine. The ch channel will be closed automatically after f returns.
 // If panic occurs inside of f it will be recovered and error will be written to the ch channel.
 // If capacity is defined or greater than zero, buffered channel will be created.
 func Go[T any](ctx context.Context, f Func[T], capacity ...int) <-chan Option[T] {
-	var ch chan Option[T]
+	return GoCtx(ctx, ignoreCtx(f), capacity...)
+}
 
-	if len(capacity) > 0 {
-		ch = make(chan Option[T], capacity[0])
-	} else {
-		ch = make(chan Option[T])
+// GoCtx safely runs function f at a new goroutine like Go and passes ctx to f,
+// so f can honor cancellation and deadline of the context.
+func GoCtx[T any](ctx context.Context, f FuncCtx[T], capacity ...int) <-chan Option[T] {
+	ch := makeChan[T](capacity...)
+
+	goCtx(ctx, f, ch)
+
+	return ch
+}
+
+// goStage runs function f at a new goroutine like Go for the stage reading the ins channels and writing to ch,
+// the stage is linked to them at the scope of ctx while it's running, see Scope.link.
+func goStage[T any](ctx context.Context, f Func[T], ch chan Option[T], ins ...any) <-chan Option[T] {
+	scope := scopeFrom(ctx)
+	if scope == nil {
+		goCtx(ctx, ignoreCtx(f), ch)
+
+		return ch
 	}
 
-	wg, _ := ctx.Value(contextKeyWG).(*sync.WaitGroup)
-	if wg != nil {
-		wg.Add(1)
+	out := (<-chan Option[T])(ch)
+
+	scope.link(out, ins...)
+
+	fn := func(ch chan<- Option[T]) error {
+		defer scope.unlink(out)
+
+		return f(ch)
 	}
 
-	cancel, _ := ctx.Value(contextKeyCancel).(context.CancelFunc)
+	goCtx(ctx, ignoreCtx(fn), ch)
 
-	go func() {
-		if wg != nil {
-			defer wg.Done()
+	return out
+}
+
+// goCtx runs function f at a new goroutine like GoCtx writing to the ch channel.
+func goCtx[T any](ctx context.Context, f FuncCtx[T], ch chan Option[T]) {
+	ctx, done := track(ctx)
+
+	spawn(
+		ctx,
+		func() {
+			defer done()
+
+			run(ctx, f, ch)
+		},
+		func(err error) {
+			defer done()
+
+			run(ctx, fail[T](err), ch)
+		},
+	)
+}
+
+// fail returns the function failing with err.
+func fail[T any](err error) FuncCtx[T] {
+	return func(context.Context, chan<- Option[T]) error { return err }
+}
+
+func ignoreCtx[T any](f Func[T]) FuncCtx[T] {
+	return func(_ context.Context, ch chan<- Option[T]) error { return f(ch) }
+}
+
+// GoWith safely runs function f at a new goroutine like Go with the context modified by opt funcs.
+func GoWith[T any](ctx context.Context, f Func[T], opt ...OptFunc) <-chan Option[T] {
+	ctx, capacity := applyTask(ctx, opt)
+
+	return Go(ctx, f, capacity...)
+}
+
+// applyTask modifies ctx of a single task by opt funcs and takes the channel capacity set by WithCapacity,
+// so it's not inherited by nested tasks.
+func applyTask(ctx context.Context, opt []OptFunc) (context.Context, []int) {
+	for _, o := range opt {
+		if o != nil {
+			ctx = o(ctx)
 		}
+	}
 
-		defer close(ch)
+	var capacity []int
+	if c, ok := ctx.Value(contextKeyCapacity).(int); ok {
+		capacity = append(capacity, c)
+		ctx = context.WithValue(ctx, contextKeyCapacity, nil)
+	}
+
+	return ctx, capacity
+}
 
-		defer func() {
-			if r := recover(); r != nil {
-				err := fmt.Errorf("recovered panic: %s:\n%s", r, string(debug.Stack()))
+// GoValue safely runs function f at a new goroutine like Go and passes its single result to the returned channel.
+// The channel is buffered, so f is never blocked by the consumer.
+func GoValue[T any](ctx context.Context, f func(ctx context.Context) (T, error)) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		value, err := f(ctx)
+		if err != nil {
+			return err
+		}
+
+		ch <- MakeValue(value)
+
+		return nil
+	}
+
+	return Go(ctx, fn, 1)
+}
 
-				sendErr := sendFailError(ctx, ch, err)
-				if sendErr != nil {
-					slog.ErrorContext(ctx, "async: failed to send error", slog.String("error", sendErr.Error()))
-				}
+// Sync converts function f to the synchronous call, so the same function can be run either by Go or inline.
+// The returned function calls f at the current goroutine and returns its first option like Await,
+// the rest of the options are discarded. Recovered panic is returned as error. The scope of ctx doesn't track the call.
+func Sync[T any](f Func[T]) func(ctx context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		ch := make(chan Option[T])
+		first := make(chan Option[T], 1)
+
+		go func() {
+			opt, ok := <-ch
+			if !ok {
+				opt = MakeErr[T](ErrChannelClosed)
+			}
 
-				if cancel != nil {
-					cancel()
-				}
+			first <- opt
 
-				return
+			for range ch {
 			}
 		}()
 
-		err := f(ch)
+		if err := call(ctx, f, ch); err != nil {
+			ch <- MakeErr[T](err)
+		}
+
+		close(ch)
+
+		opt := <-first
+
+		return opt.Value(), opt.Err()
+	}
+}
+
+// GoDetached safely runs function f at a new goroutine like Go for background tasks nobody awaits.
+// The error returned by f or recovered panic is passed to errSink instead of the channel and doesn't fail the scope,
+// if errSink is nil, the error is logged. Values sent by f are discarded. The scope of ctx still tracks the task.
+func GoDetached(ctx context.Context, f Func[struct{}], errSink func(error)) {
+	fn := func(ch chan<- Option[struct{}]) error {
+		err := call(ctx, f, ch)
+		if err == nil {
+			return nil
+		}
+
+		if errSink == nil {
+			loggerFrom(ctx).ErrorContext(ctx, "async: detached task failed", "error", err.Error())
+
+			return nil
+		}
+
+		errSink(err)
+
+		return nil
+	}
+
+	ch := Go(ctx, fn)
+
+	go func() {
+		for range ch {
+		}
+	}()
+}
+
+// track registers a new task at the scope of ctx and returns the context of the task.
+// Returned func must be called when the task is done.
+func track(ctx context.Context) (_ context.Context, done func()) {
+	scope := scopeFrom(ctx)
+	if scope == nil {
+		return ctx, func() {}
+	}
+
+	for s := scope; s != nil; s = s.parent {
+		s.wg.Add(1)
+	}
+
+	task := scope.register(nameFrom(ctx))
+
+	return context.WithValue(ctx, contextKeyTask, task), func() {
+		scope.finish(task)
+
+		for s := scope; s != nil; s = s.parent {
+			s.wg.Done()
+		}
+	}
+}
+
+func makeChan[T any](capacity ...int) chan Option[T] {
+	if len(capacity) > 0 {
+		return make(chan Option[T], capacity[0])
+	}
+
+	return make(chan Option[T])
+}
+
+// run calls f at the current goroutine and closes the ch channel after f returns.
+// Panics and errors are handled the same way as described for Go. It returns the task's error passed to ch.
+func run[T any](ctx context.Context, f FuncCtx[T], ch chan Option[T]) (err error) {
+	sendFail := sendFailError[T]
+
+	if guarded(ctx) {
+		defer closeGuarded(ctx, ch)
+		defer guard(ctx, ch)()
+
+		sendFail = sendFailGuarded[T]
+	} else {
+		defer close(ch)
+	}
+
+	ctx, cleanups := withCleanups(ctx)
+	defer cleanups.run()
+
+	if sem := semaphoreFrom(ctx); sem != nil {
+		if err := sem.Acquire(ctx); err != nil {
+			f = func(context.Context, chan<- Option[T]) error { return err }
+		} else {
+			defer sem.Release()
+		}
+	}
+
+	if limiter := limiterFrom(ctx); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			f = func(context.Context, chan<- Option[T]) error { return err }
+		}
+	}
+
+	ctx, span := startSpan(ctx)
+
+	f = labeled(ctx, f)
+	f = watched(ctx, f)
+
+	metrics := metricsFrom(ctx)
+	clock := clockFrom(ctx)
+	start := clock.Now()
+
+	metrics.TaskStarted(ctx)
+
+	var panicked bool
+
+	defer func() {
+		recordExit(ctx, err, panicked)
+
+		metrics.TaskFinished(ctx, clock.Now().Sub(start), err)
+
 		if err != nil {
-			sendErr := sendFailError(ctx, ch, err)
-			if sendErr != nil {
-				slog.ErrorContext(ctx, "async: failed to send error", slog.String("error", sendErr.Error()))
-			}
+			span.RecordError(err)
+		}
+
+		span.End()
+	}()
+
+	defer func() {
+		if noRecover(ctx) {
+			return
+		}
 
-			if cancel != nil {
-				cancel()
+		if r := recover(); r != nil {
+			err = newTaskError(ctx, start, recovered(ctx, r), true)
+			panicked = true
+
+			metrics.TaskPanicked(ctx)
+			span.AddEvent("panic")
+
+			sendErr := sendFail(ctx, ch, err)
+			if sendErr != nil {
+				loggerFrom(ctx).ErrorContext(ctx, "async: failed to send error", "error", sendErr.Error())
 			}
 
 			return
 		}
 	}()
 
-	return ch
+	err = f(ctx, ch)
+	if err != nil {
+		err = newTaskError(ctx, start, err, false)
+
+		sendErr := sendFail(ctx, ch, err)
+		if sendErr != nil {
+			loggerFrom(ctx).ErrorContext(ctx, "async: failed to send error", "error", sendErr.Error())
+		}
+
+		return err
+	}
+
+	return nil
 }
 
 // Group runs g(i) functions in parallel, their output falls into one channel.
 // n is a count of passed functions. i is ranged from 0 to n-1.
 func Group[T any](ctx context.Context, g func(i int) Func[T], n int, capacity ...int) <-chan Option[T] {
+	ctx, prog := withProgress(ctx, n)
+
+	scope := scopeFrom(ctx)
+	ch := makeChan[T](capacity...)
+
 	fn := func(outCh chan<- Option[T]) error {
 		var wg sync.WaitGroup
 
 		wg.Add(n)
 
 		for i := 0; i < n; i++ {
-			inCh := Go(ctx, g(i), 1)
+			inCh := goUpstream(withIndex(ctx, i), g(i), ch, 1)
 
 			go func() {
 				defer wg.Done()
+				defer prog.finish()
 
-				for v := range inCh {
-					outCh <- v
-				}
+				relay(scope, inCh, outCh)
 			}()
 		}
 
@@ -130,36 +398,143 @@ func Group[T any](ctx context.Context, g func(i int) Func[T], n int, capacity ..
 		return nil
 	}
 
-	return Go(ctx, fn, capacity...)
+	return goStage(withoutLimits(ctx), fn, ch)
 }
 
-// Await reads channel ch and unwraps option to value and error.
-// Can be interrupted by closed context.
+// goUpstream runs function f at a new goroutine like Go, its channel is linked as the input of the stage
+// of the out channel, see Scope.link.
+func goUpstream[T, U any](ctx context.Context, f Func[T], out chan Option[U], capacity ...int) <-chan Option[T] {
+	ch := makeChan[T](capacity...)
+
+	if scope := scopeFrom(ctx); scope != nil {
+		scope.link((<-chan Option[U])(out), (<-chan Option[T])(ch))
+	}
+
+	goCtx(ctx, ignoreCtx(f), ch)
+
+	return ch
+}
+
+// relay passes options of the in channel to the out channel until in is closed,
+// then the failure of its task kept by the scope, if any.
+func relay[T any](scope *Scope, in <-chan Option[T], out chan<- Option[T]) {
+	for v := range in {
+		out <- v
+	}
+
+	if opt, ok := takeFailure(scope, in); ok {
+		out <- opt
+	}
+}
+
+// Await reads channel ch and unwraps option to value and error. Partial value is returned along with its error.
+// Can be interrupted by closed context. If the task of ch failed, its own error is returned even if the failure
+// has cancelled the context's scope, failures of other tasks of the scope are reported by Err.
 func Await[T any](ctx context.Context, ch <-chan Option[T]) (value T, err error) {
-	wg, _ := ctx.Value(contextKeyWG).(*sync.WaitGroup)
-	if wg != nil {
-		defer wg.Wait()
+	value, err = await(ctx, ch)
+	if err != nil && ctx.Err() != nil {
+		Abandon(ch)
 	}
 
-	errCh, _ := ctx.Value(contextKeyErrorsChan).(chan error)
-	if errCh == nil {
-		errCh = make(chan error)
+	return value, err
+}
+
+func await[T any](ctx context.Context, ch <-chan Option[T]) (value T, err error) {
+	opt, ok, err := recv(ctx, ch)
+	if err != nil {
+		return value, err
 	}
 
+	if !ok {
+		return value, ErrChannelClosed
+	}
+
+	return opt.Value(), opt.Err()
+}
+
+// recv reads the next option of the ch channel. ok is false if the channel is closed.
+// The failure of the channel's task kept by the context's scope is received as the last option.
+// If ch is the output of stages and the scope is cancelled by the failure of their upstream task, its error is received.
+// Can be interrupted by closed context, unless the channel is ready.
+func recv[T any](ctx context.Context, ch <-chan Option[T]) (opt Option[T], ok bool, err error) {
+	scope := scopeFrom(ctx)
+
+	checkOwner(ctx, ch)
+
 	select {
 	case <-ctx.Done():
-		return value, ctx.Err()
+		if opt, ok = upstreamFailure(scope, ch); ok {
+			return opt, true, nil
+		}
 
-	case err = <-errCh:
-		return value, err
+		// The task's own failure wins over the cancellation of the scope caused by it.
+		select {
+		case opt, ok = <-ch:
+		default:
+			if opt, ok = takeFailure(scope, ch); ok {
+				return opt, true, nil
+			}
 
-	case opt, ok := <-ch:
-		if !ok {
-			return value, ErrChannelClosed
+			return opt, false, ctx.Err()
 		}
 
-		return opt.Value(), opt.Err()
+	case opt, ok = <-ch:
+	}
+
+	if !ok {
+		opt, ok = takeFailure(scope, ch)
 	}
+
+	// The stage interrupted by the cancellation fails or closes its channel, then the upstream failure is its reason.
+	if (!ok || opt.Err() != nil) && ctx.Err() != nil {
+		if failure, found := upstreamFailure(scope, ch); found {
+			return failure, true, nil
+		}
+	}
+
+	return opt, ok, nil
+}
+
+// upstreamFailure returns the failure of the upstream task feeding the ch channel through stages,
+// which wins over the cancellation of the scope caused by it, see Scope.upstreamFailure.
+func upstreamFailure[T any](scope *Scope, ch <-chan Option[T]) (opt Option[T], ok bool) {
+	if scope == nil {
+		return opt, false
+	}
+
+	err := scope.upstreamFailure(ch)
+	if err == nil {
+		return opt, false
+	}
+
+	return makeFinal[T](err), true
+}
+
+// keptFailure returns the failure of the ch channel's task kept by its scope, for the readers having no context.
+func keptFailure[T any](ch <-chan Option[T]) (opt Option[T], ok bool) {
+	scope, found := failedScopes.Load(ch)
+	if !found {
+		return opt, false
+	}
+
+	return takeFailure(scope.(*Scope), ch)
+}
+
+func takeFailure[T any](scope *Scope, ch <-chan Option[T]) (opt Option[T], ok bool) {
+	if scope == nil {
+		return opt, false
+	}
+
+	err := scope.takeFailure(ch)
+	if err == nil {
+		return opt, false
+	}
+
+	return makeFinal[T](err), true
+}
+
+func makeFinal[T any](err error) Option[T] {
+	return Option[T]{err: err, final: true}
 }
 
 // TrySend sends value to the ch channel, blocked until context closed or value passed to the channel.
@@ -182,48 +557,81 @@ func TrySendError[T any](ctx context.Context, ch chan<- Option[T], err error) (_
 	}
 }
 
-func sendFailError[T any](ctx context.Context, ch chan<- Option[T], err error) (_ error) {
-	errCh, _ := ctx.Value(contextKeyErrorsChan).(chan error)
-	if errCh == nil {
-		select {
-		case ch <- MakeErr[T](err):
-			return nil
-		default:
-			return err
-		}
+// sendFailError passes err of the failed task to the ch channel without blocking.
+// If the task belongs to a scope, the error which can't be sent is kept by the scope as the failure of the channel,
+// then the scope fails. Without a scope, it waits for the consumer until ctx is done
+// and returns err if it's lost.
+func sendFailError[T any](ctx context.Context, ch chan Option[T], err error) (_ error) {
+	scope := scopeFrom(ctx)
+
+	lost := passFailure(ctx, scope, ch, err)
+
+	if scope != nil {
+		scope.fail((<-chan Option[T])(ch), err)
+
+		return nil
+	}
+
+	if lost {
+		return err
 	}
 
+	return nil
+}
+
+// passFailure passes err to the ch channel or to the scope keeping it, see sendFailError. It reports whether err is lost.
+func passFailure[T any](ctx context.Context, scope *Scope, ch chan Option[T], err error) (lost bool) {
 	select {
-	case errCh <- err:
-		return nil
+	case ch <- makeFinal[T](err):
+		return false
 	default:
-		return err
+	}
+
+	if scope != nil {
+		scope.setFailure((<-chan Option[T])(ch), err)
+
+		return false
+	}
+
+	select {
+	case ch <- makeFinal[T](err):
+		return false
+	case <-ctx.Done():
+		return true
 	}
 }
 
 type OptFunc func(ctx context.Context) context.Context
 
 // With returns the new context containing optional values from opt funcs and context cancel func.
+// The context belongs to the new Scope, see NewScope.
 func With(ctx context.Context, opt ...OptFunc) (context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithCancel(ctx)
+	scope := NewScope(ctx, opt...)
 
-	ctx = context.WithValue(ctx, contextKeyCancel, cancel)
+	return scope.ctx, scope.Cancel
+}
 
-	ctx = context.WithValue(ctx, contextKeyErrorsChan, make(chan error, 1))
+// Detach returns the context keeping values of ctx, e.g. the logger or trace ids, but detached from its cancellation,
+// deadline and scope, for fire-and-forget tasks which must outlive ctx.
+func Detach(ctx context.Context) context.Context {
+	return context.WithValue(context.WithoutCancel(ctx), contextKeyScope, (*Scope)(nil))
+}
 
-	for _, o := range opt {
-		if o != nil {
-			ctx = o(ctx)
-		}
+// WithCapacity sets the capacity of the channel created by GoWith or Pool.SubmitWith.
+func WithCapacity(capacity int) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyCapacity, capacity)
 	}
-
-	return ctx, cancel
+	return fn
 }
 
+// Wait used to make Await wait for all goroutines of the scope, which could deadlock while tasks are still streaming.
+//
+// Deprecated: Await doesn't wait for the scope anymore and the option does nothing.
+// Call WaitAll or WaitAllTimeout explicitly instead.
 func Wait() OptFunc {
 	fn := func(ctx context.Context) context.Context {
-		wg := new(sync.WaitGroup)
-		return context.WithValue(ctx, contextKeyWG, wg)
+		return ctx
 	}
 	return fn
 }
@@ -9,6 +9,69 @@ import (
 	"github.com/WinPooh32/async/v2"
 )
 
+func TestOption_Unwrap(t *testing.T) {
+	testErr := errors.New("test error")
+
+	v, err := async.MakeValue(1).Unwrap()
+	if v != 1 || err != nil {
+		t.Error(v, err)
+	}
+
+	_, err = async.MakeErr[int](testErr).Unwrap()
+	if err != testErr {
+		t.Error(err)
+	}
+
+	if async.MakeValue(1).IsErr() || !async.MakeErr[int](testErr).IsErr() {
+		t.Fail()
+	}
+}
+
+func TestOption_Must(t *testing.T) {
+	testErr := errors.New("test error")
+
+	if async.MakeValue(1).Must() != 1 {
+		t.Fail()
+	}
+
+	defer func() {
+		if r := recover(); r != testErr {
+			t.Error(r)
+		}
+	}()
+
+	async.MakeErr[int](testErr).Must()
+}
+
+func TestMakeValueErr(t *testing.T) {
+	testErr := errors.New("truncated")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[string]) error {
+			ch <- async.MakeValue("full")
+			ch <- async.MakeValueErr("part", testErr)
+
+			return nil
+		},
+	)
+
+	values, err := async.Collect(ctx, ch)
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if len(values) != 2 || values[1] != "part" {
+		t.Error(values)
+	}
+
+	if async.MakeValueErr(1, nil).IsPartial() || !async.MakeValueErr(1, testErr).IsPartial() {
+		t.Fail()
+	}
+}
+
 func TestGo_Value(t *testing.T) {
 	const testValue = 1
 
@@ -246,6 +309,58 @@ func TestGo_Sync(t *testing.T) {
 	}
 }
 
+func TestGoCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch := async.GoCtx(
+		ctx,
+		func(ctx context.Context, ch chan<- async.Option[int]) error {
+			return ctx.Err()
+		},
+		1,
+	)
+
+	opt := <-ch
+	if !errors.Is(opt.Err(), context.Canceled) {
+		t.Error(opt.Err())
+	}
+}
+
+func TestGoValue(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.GoValue(ctx, func(ctx context.Context) (string, error) {
+		return "value", nil
+	})
+
+	v, err := async.Await(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v != "value" {
+		t.Fail()
+	}
+}
+
+func TestGoValue_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.GoValue(ctx, func(ctx context.Context) (string, error) {
+		return "", testErr
+	})
+
+	_, err := async.Await(ctx, ch)
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
 func TestAwait(t *testing.T) {
 	const testValue = 1
 
@@ -355,3 +470,89 @@ func TestAwaitContext_Err(t *testing.T) {
 
 	t.Fail()
 }
+
+func TestDetach(t *testing.T) {
+	type key struct{}
+
+	ctx, cancel := async.With(context.WithValue(context.Background(), key{}, "value"))
+
+	detached := async.Detach(ctx)
+
+	cancel()
+
+	if detached.Err() != nil || detached.Value(key{}) != "value" {
+		t.Error(detached.Err(), detached.Value(key{}))
+
+		return
+	}
+
+	ch := async.Go(detached, func(ch chan<- async.Option[int]) error {
+		return errors.New("test error")
+	}, 1)
+
+	if _, err := async.Await(detached, ch); err == nil {
+		t.Fail()
+	}
+
+	if err := async.Err(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGoDetached(t *testing.T) {
+	ctx, cancel := async.With(context.Background())
+	defer cancel()
+
+	var errs []error
+
+	async.GoDetached(
+		ctx,
+		func(ch chan<- async.Option[struct{}]) error {
+			ch <- async.MakeValue(struct{}{})
+
+			panic("something went wrong!")
+		},
+		func(err error) { errs = append(errs, err) },
+	)
+
+	if err := async.WaitAll(ctx); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	var panicErr *async.PanicError
+	if len(errs) != 1 || !errors.As(errs[0], &panicErr) {
+		t.Error(errs)
+	}
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+
+	f := async.Sync(func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+		ch <- async.MakeValue(2)
+
+		return nil
+	})
+
+	if v, err := f(ctx); err != nil || v != 1 {
+		t.Error(v, err)
+	}
+
+	f = async.Sync(func(ch chan<- async.Option[int]) error {
+		panic("something went wrong!")
+	})
+
+	var panicErr *async.PanicError
+	if _, err := f(ctx); !errors.As(err, &panicErr) {
+		t.Error(err)
+	}
+
+	f = async.Sync(func(ch chan<- async.Option[int]) error { return nil })
+
+	if _, err := f(ctx); !errors.Is(err, async.ErrChannelClosed) {
+		t.Error(err)
+	}
+}
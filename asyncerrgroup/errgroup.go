@@ -0,0 +1,39 @@
+// Package asyncerrgroup bridges async scopes and golang.org/x/sync/errgroup groups.
+package asyncerrgroup
+
+import (
+	"context"
+
+	"github.com/WinPooh32/async/v2"
+	"golang.org/x/sync/errgroup"
+)
+
+// Go runs function f like async.Go as a member of the errgroup g: g.Wait waits for f and its nested tasks,
+// the error returned by f or recovered panic is returned to g. Pass the errgroup's context as ctx to share its cancellation.
+func Go[T any](ctx context.Context, g *errgroup.Group, f async.Func[T], capacity ...int) <-chan async.Option[T] {
+	scope := async.NewScope(ctx)
+
+	ch := async.Go(scope.Context(), f, capacity...)
+
+	g.Go(func() error {
+		defer scope.Cancel()
+
+		return scope.Wait()
+	})
+
+	return ch
+}
+
+// ToErrgroup makes the scope a member of the errgroup g: g.Wait waits for the scope's tasks and returns their first error.
+// It must be called after the tasks are started, because waiting for the scope without tasks returns immediately.
+// Create the scope from the errgroup's context to share its cancellation.
+func ToErrgroup(g *errgroup.Group, scope *async.Scope) {
+	g.Go(scope.Wait)
+}
+
+// FromErrgroup makes the errgroup g a member of the scope: scope.Wait waits for g and the first error of g fails the scope.
+// It must be called after the functions of g are started, because waiting for g without functions returns immediately.
+// Create the errgroup from the scope's context to share its cancellation.
+func FromErrgroup(scope *async.Scope, g *errgroup.Group) {
+	scope.Go(func(context.Context) error { return g.Wait() })
+}
@@ -0,0 +1,69 @@
+package asyncerrgroup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+	"github.com/WinPooh32/async/v2/asyncerrgroup"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestGo(t *testing.T) {
+	testErr := errors.New("test error")
+
+	g, ctx := errgroup.WithContext(context.Background())
+
+	asyncerrgroup.Go(ctx, g, func(ch chan<- async.Option[int]) error {
+		return testErr
+	})
+
+	g.Go(func() error {
+		<-ctx.Done()
+
+		return nil
+	})
+
+	if err := g.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestToErrgroup(t *testing.T) {
+	testErr := errors.New("test error")
+
+	g, ctx := errgroup.WithContext(context.Background())
+
+	scope := async.NewScope(ctx)
+
+	scope.Go(func(ctx context.Context) error { return testErr })
+
+	asyncerrgroup.ToErrgroup(g, scope)
+
+	if err := g.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestFromErrgroup(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background())
+
+	g, ctx := errgroup.WithContext(scope.Context())
+
+	g.Go(func() error { return testErr })
+
+	asyncerrgroup.FromErrgroup(scope, g)
+
+	scope.Go(func(context.Context) error {
+		<-ctx.Done()
+
+		return nil
+	})
+
+	if err := scope.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
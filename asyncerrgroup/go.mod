@@ -0,0 +1,10 @@
+module github.com/WinPooh32/async/v2/asyncerrgroup
+
+go 1.23
+
+require (
+	github.com/WinPooh32/async/v2 v2.0.0
+	golang.org/x/sync v0.10.0
+)
+
+replace github.com/WinPooh32/async/v2 => ../
@@ -0,0 +1,59 @@
+// Package asyncexpvar publishes async task metrics with expvar.
+package asyncexpvar
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+// Metrics implements async.Metrics by counters of the expvar map.
+type Metrics struct {
+	started    expvar.Int
+	finished   expvar.Int
+	failed     expvar.Int
+	panicked   expvar.Int
+	running    expvar.Int
+	queueDepth expvar.Int
+	durationNs expvar.Int
+}
+
+var _ async.Metrics = (*Metrics)(nil)
+
+// New publishes the expvar map with the name and returns metrics writing to it.
+// Like expvar.Publish, it panics if the name is already registered.
+func New(name string) *Metrics {
+	m := new(Metrics)
+
+	vars := expvar.NewMap(name)
+	vars.Set("started", &m.started)
+	vars.Set("finished", &m.finished)
+	vars.Set("failed", &m.failed)
+	vars.Set("panicked", &m.panicked)
+	vars.Set("running", &m.running)
+	vars.Set("queue_depth", &m.queueDepth)
+	vars.Set("duration_ns", &m.durationNs)
+
+	return m
+}
+
+func (m *Metrics) TaskStarted(context.Context) {
+	m.started.Add(1)
+	m.running.Add(1)
+}
+
+func (m *Metrics) TaskFinished(_ context.Context, duration time.Duration, err error) {
+	m.finished.Add(1)
+	m.running.Add(-1)
+	m.durationNs.Add(int64(duration))
+
+	if err != nil {
+		m.failed.Add(1)
+	}
+}
+
+func (m *Metrics) TaskPanicked(context.Context) { m.panicked.Add(1) }
+
+func (m *Metrics) QueueDepth(_ context.Context, depth int) { m.queueDepth.Set(int64(depth)) }
@@ -0,0 +1,54 @@
+package asyncexpvar_test
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+	"github.com/WinPooh32/async/v2/asyncexpvar"
+)
+
+// runs keeps names unique when the test is repeated with -count.
+var runs atomic.Int32
+
+func TestMetrics(t *testing.T) {
+	name := fmt.Sprintf("async_test_%d", runs.Add(1))
+
+	m := asyncexpvar.New(name)
+
+	ctx, cancel := async.With(context.Background(), async.WithMetrics(m))
+	defer cancel()
+
+	async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			return nil
+		},
+	)
+
+	async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			panic("something went wrong!")
+		},
+	)
+
+	_ = async.WaitAll(ctx)
+
+	vars := expvar.Get(name).(*expvar.Map)
+
+	for name, want := range map[string]string{
+		"started":  "2",
+		"finished": "2",
+		"failed":   "1",
+		"panicked": "1",
+		"running":  "0",
+	} {
+		if got := vars.Get(name).String(); got != want {
+			t.Error(name, got, want)
+		}
+	}
+}
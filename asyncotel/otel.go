@@ -0,0 +1,38 @@
+// Package asyncotel adapts OpenTelemetry tracers for async.WithTracing.
+package asyncotel
+
+import (
+	"context"
+
+	"github.com/WinPooh32/async/v2"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer wraps t, so it can be passed to async.WithTracing.
+func Tracer(t trace.Tracer) async.Tracer {
+	return tracer{t: t}
+}
+
+type tracer struct {
+	t trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, name string) (context.Context, async.Span) {
+	ctx, s := t.t.Start(ctx, name)
+
+	return ctx, span{s: s}
+}
+
+type span struct {
+	s trace.Span
+}
+
+func (s span) RecordError(err error) {
+	s.s.RecordError(err)
+	s.s.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) AddEvent(name string) { s.s.AddEvent(name) }
+
+func (s span) End() { s.s.End() }
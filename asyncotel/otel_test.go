@@ -0,0 +1,52 @@
+package asyncotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+	"github.com/WinPooh32/async/v2/asyncotel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+
+	ctx, cancel := async.With(ctx, async.WithTracing(asyncotel.Tracer(tracer)))
+	defer cancel()
+
+	async.GoCtx(
+		ctx,
+		func(ctx context.Context, ch chan<- async.Option[int]) error {
+			return errors.New("test error")
+		},
+	)
+
+	_ = async.WaitAll(ctx)
+
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Error(len(spans))
+
+		return
+	}
+
+	task := spans[0]
+
+	if task.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("task span is not linked to the parent")
+	}
+
+	if task.Status().Code != codes.Error {
+		t.Error(task.Status())
+	}
+}
@@ -0,0 +1,137 @@
+// Package asynctest provides helpers for testing code built on async: assertions on option channels,
+// polling of conditions, the fake clock and the detector of leaked scope tasks.
+package asynctest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+// Eventually polls cond every tick until it returns true, the test fails if it doesn't happen within timeout.
+func Eventually(t testing.TB, cond func() bool, timeout, tick time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Errorf("condition is not satisfied within %s", timeout)
+
+			return
+		}
+
+		<-time.After(tick)
+	}
+}
+
+// ExpectValue reads an option of the ch channel and fails the test if it's not the want value,
+// or it's not received within timeout.
+func ExpectValue[T comparable](t testing.TB, ch <-chan async.Option[T], want T, timeout time.Duration) {
+	t.Helper()
+
+	opt, ok := receive(t, ch, timeout)
+	if !ok {
+		return
+	}
+
+	if err := opt.Err(); err != nil {
+		t.Errorf("expected value %v, got error: %v", want, err)
+
+		return
+	}
+
+	if v := opt.Value(); v != want {
+		t.Errorf("expected value %v, got %v", want, v)
+	}
+}
+
+// ExpectErr reads an option of the ch channel and fails the test if its error doesn't match target by errors.Is,
+// or it's not received within timeout. Nil target matches any error.
+func ExpectErr[T any](t testing.TB, ch <-chan async.Option[T], target error, timeout time.Duration) {
+	t.Helper()
+
+	opt, ok := receive(t, ch, timeout)
+	if !ok {
+		return
+	}
+
+	err := opt.Err()
+
+	switch {
+	case err == nil:
+		t.Errorf("expected error, got value %v", opt.Value())
+	case target != nil && !errors.Is(err, target):
+		t.Errorf("expected error %v, got %v", target, err)
+	}
+}
+
+// ExpectClosedWithin reads and discards options of the ch channel and fails the test
+// if the channel is not closed within d.
+func ExpectClosedWithin[T any](t testing.TB, ch <-chan async.Option[T], d time.Duration) {
+	t.Helper()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			t.Errorf("channel is not closed within %s", d)
+
+			return
+
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// ExpectNoLeaks fails the test if tasks of the scope are still running when the test and its cleanups
+// registered before finish. The tasks are given a grace period of 100 milliseconds to exit.
+func ExpectNoLeaks(t testing.TB, scope *async.Scope) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		deadline := time.Now().Add(100 * time.Millisecond)
+
+		for scope.NumGoroutine() > 0 {
+			if time.Now().After(deadline) {
+				for _, task := range scope.Tasks() {
+					if task.State == async.TaskRunning {
+						t.Errorf("task %q started at %s outlived the test", task.Name, task.Started.Format(time.RFC3339Nano))
+					}
+				}
+
+				return
+			}
+
+			<-time.After(time.Millisecond)
+		}
+	})
+}
+
+func receive[T any](t testing.TB, ch <-chan async.Option[T], timeout time.Duration) (opt async.Option[T], ok bool) {
+	t.Helper()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		t.Errorf("no option is received within %s", timeout)
+
+		return opt, false
+
+	case opt, ok = <-ch:
+		if !ok {
+			t.Error("channel is closed")
+		}
+
+		return opt, ok
+	}
+}
@@ -0,0 +1,77 @@
+package asynctest_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+	"github.com/WinPooh32/async/v2/asynctest"
+)
+
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(...any) { r.failed = true }
+
+func (r *recorder) Errorf(string, ...any) { r.failed = true }
+
+func TestExpect(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+		ch <- async.MakeErr[int](testErr)
+
+		return nil
+	})
+
+	asynctest.ExpectValue(t, ch, 1, time.Second)
+	asynctest.ExpectErr(t, ch, testErr, time.Second)
+	asynctest.ExpectClosedWithin(t, ch, time.Second)
+
+	r := &recorder{}
+
+	asynctest.ExpectValue(r, async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(2)
+
+		return nil
+	}), 1, time.Second)
+
+	if !r.failed {
+		t.Fail()
+	}
+}
+
+func TestEventually(t *testing.T) {
+	var done atomic.Bool
+
+	go func() {
+		<-time.After(10 * time.Millisecond)
+		done.Store(true)
+	}()
+
+	asynctest.Eventually(t, done.Load, time.Second, time.Millisecond)
+}
+
+func TestExpectNoLeaks(t *testing.T) {
+	scope := async.NewScope(context.Background())
+
+	asynctest.ExpectNoLeaks(t, scope)
+
+	scope.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return nil
+	})
+
+	t.Cleanup(scope.Cancel)
+}
@@ -0,0 +1,193 @@
+package asynctest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+// FakeClock is the async.Clock whose time moves only by Advance.
+// Pass it to the code under test with async.WithClock.
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+var _ async.Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns the fake clock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTimer returns the timer firing when the clock is advanced by d.
+func (c *FakeClock) NewTimer(d time.Duration) async.Timer {
+	return c.start(d, 0, nil)
+}
+
+// NewTicker returns the ticker firing every time the clock is advanced by d.
+func (c *FakeClock) NewTicker(d time.Duration) async.Ticker {
+	return fakeTicker{c.start(d, d, nil)}
+}
+
+// AfterFunc returns the timer calling f at its own goroutine when the clock is advanced by d.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) async.Timer {
+	return c.start(d, 0, f)
+}
+
+// Advance moves the time of the clock forward by d and fires the expired timers in their order.
+// Like the system ones, timers don't block on their channels, a tick is dropped if the previous one is not read yet.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := c.now.Add(d)
+
+	for {
+		t := c.next(end)
+		if t == nil {
+			break
+		}
+
+		c.now = t.when
+
+		if t.f != nil {
+			go t.f()
+		} else {
+			select {
+			case t.c <- c.now:
+			default:
+			}
+		}
+
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			c.remove(t)
+		}
+	}
+
+	c.now = end
+}
+
+// BlockUntil blocks until at least n timers and tickers are waiting for the clock,
+// so the code under test is known to have started its timers before Advance is called.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *FakeClock) start(d, period time.Duration, f func()) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1), period: period, f: f}
+
+	c.add(t, d)
+
+	return t
+}
+
+func (c *FakeClock) add(t *fakeTimer, d time.Duration) {
+	t.when = c.now.Add(d)
+
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+}
+
+// next returns the earliest timer expiring not later than end.
+func (c *FakeClock) next(end time.Time) *fakeTimer {
+	var next *fakeTimer
+
+	for _, t := range c.timers {
+		if !t.when.After(end) && (next == nil || t.when.Before(next.when)) {
+			next = t
+		}
+	}
+
+	return next
+}
+
+// remove removes the t timer and reports whether it was waiting.
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	c      chan time.Time
+	when   time.Time
+	period time.Duration
+	f      func()
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	if t.f != nil {
+		return nil
+	}
+
+	return t.c
+}
+
+// Stop stops the timer and discards its unread tick like the system timers since Go 1.23.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+
+	return t.clock.remove(t)
+}
+
+// Reset restarts the timer to fire after d and discards its unread tick.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+
+	active := t.clock.remove(t)
+
+	t.clock.add(t, d)
+
+	return active
+}
+
+type fakeTicker struct{ t *fakeTimer }
+
+func (t fakeTicker) C() <-chan time.Time { return t.t.c }
+
+func (t fakeTicker) Stop() { t.t.Stop() }
+
+func (t *fakeTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
@@ -0,0 +1,177 @@
+package asynctest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+	"github.com/WinPooh32/async/v2/asynctest"
+)
+
+func TestFakeClock_After(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Unix(0, 0))
+
+	ctx := async.WithClock(clock)(context.Background())
+
+	ch := async.After(ctx, time.Hour, func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+
+		return nil
+	})
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+
+	asynctest.ExpectValue(t, ch, 1, time.Second)
+}
+
+func TestFakeClock_Every(t *testing.T) {
+	const testTicks = 3
+
+	clock := asynctest.NewFakeClock(time.Unix(0, 0))
+
+	ctx, cancel := context.WithCancel(async.WithClock(clock)(context.Background()))
+	defer cancel()
+
+	ch := async.Every(ctx, time.Minute, func(ch chan<- async.Option[time.Time]) error {
+		ch <- async.MakeValue(clock.Now())
+
+		return nil
+	})
+
+	clock.BlockUntil(1)
+
+	for i := 1; i <= testTicks; i++ {
+		clock.Advance(time.Minute)
+
+		asynctest.ExpectValue(t, ch, time.Unix(0, 0).Add(time.Duration(i)*time.Minute), time.Second)
+	}
+
+	cancel()
+
+	asynctest.ExpectClosedWithin(t, ch, time.Second)
+}
+
+func TestFakeClock_Debounce(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Unix(0, 0))
+
+	ctx := async.WithClock(clock)(context.Background())
+
+	in := make(chan async.Option[int])
+	out := async.Debounce(ctx, in, time.Second)
+
+	testErr := errors.New("test error")
+
+	in <- async.MakeValue(1)
+	in <- async.MakeValue(2)
+
+	// Errors are passed immediately, so the timer is reset by the previous value once the error is received.
+	in <- async.MakeErr[int](testErr)
+	asynctest.ExpectErr(t, out, testErr, time.Second)
+
+	clock.Advance(time.Second - time.Millisecond)
+	in <- async.MakeErr[int](testErr)
+	asynctest.ExpectErr(t, out, testErr, time.Second)
+
+	clock.Advance(time.Millisecond)
+
+	asynctest.ExpectValue(t, out, 2, time.Second)
+
+	close(in)
+
+	asynctest.ExpectClosedWithin(t, out, time.Second)
+}
+
+func TestFakeClock_GroupWithTimeout(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Unix(0, 0))
+
+	ctx := async.WithClock(clock)(context.Background())
+
+	ch := async.GroupWithTimeout(ctx, func(i int) async.FuncCtx[int] {
+		return func(ctx context.Context, ch chan<- async.Option[int]) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		}
+	}, 1, time.Minute)
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	asynctest.ExpectErr(t, ch, context.DeadlineExceeded, time.Second)
+}
+
+func TestFakeClock_Memo(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Unix(0, 0))
+
+	ctx := async.WithClock(clock)(context.Background())
+
+	memo := async.Memoize[string, int](time.Minute)
+
+	var calls int
+
+	f := func(ch chan<- async.Option[int]) error {
+		calls++
+		ch <- async.MakeValue(calls)
+
+		return nil
+	}
+
+	for _, d := range []time.Duration{0, 30 * time.Second, 30 * time.Second} {
+		clock.Advance(d)
+
+		if _, err := memo.Get(ctx, "key", f); err != nil {
+			t.Error(err)
+
+			return
+		}
+	}
+
+	// The value is cached for the minute, then it expires.
+	if calls != 2 {
+		t.Error(calls)
+	}
+}
+
+func TestFakeClock_Window(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Unix(0, 0))
+
+	ctx := async.WithClock(clock)(context.Background())
+
+	in := make(chan async.Option[int])
+	out := async.Window(ctx, in, time.Second)
+
+	clock.BlockUntil(1)
+
+	in <- async.MakeValue(1)
+	in <- async.MakeValue(2)
+
+	// Errors are passed immediately, so both values are in the window once the error is received.
+	testErr := errors.New("test error")
+
+	in <- async.MakeErr[int](testErr)
+	asynctest.ExpectErr(t, out, testErr, time.Second)
+
+	clock.Advance(time.Second)
+
+	if w := <-out; len(w.Value()) != 2 || w.Value()[1] != 2 {
+		t.Error(w.Value())
+	}
+
+	clock.Advance(time.Second)
+
+	if w := <-out; w.Value() != nil {
+		t.Error(w.Value())
+	}
+
+	in <- async.MakeValue(3)
+	close(in)
+
+	if w := <-out; len(w.Value()) != 1 || w.Value()[0] != 3 {
+		t.Error(w.Value())
+	}
+
+	asynctest.ExpectClosedWithin(t, out, time.Second)
+}
@@ -0,0 +1,535 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrAwaitTimeout = errors.New("await timeout")
+
+// AwaitAll awaits one value from each of chans and returns values in the order of chans.
+// It fails fast: the first error cancels the remaining waits and is returned.
+func AwaitAll[T any](ctx context.Context, chans ...<-chan Option[T]) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i     int
+		value T
+		err   error
+	}
+
+	resCh := make(chan result, len(chans))
+
+	for i, ch := range chans {
+		go func(i int, ch <-chan Option[T]) {
+			value, err := Await(ctx, ch)
+			resCh <- result{i: i, value: value, err: err}
+		}(i, ch)
+	}
+
+	values := make([]T, len(chans))
+
+	for range chans {
+		res := <-resCh
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		values[res.i] = res.value
+	}
+
+	return values, nil
+}
+
+// Await2 concurrently awaits values of the chA and chB channels of different types.
+// It fails fast like AwaitAll: the first error cancels the remaining wait and is returned.
+func Await2[A, B any](ctx context.Context, chA <-chan Option[A], chB <-chan Option[B]) (A, B, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		a A
+		b B
+	)
+
+	errCh := make(chan error, 2)
+
+	go awaitInto(ctx, cancel, chA, &a, errCh)
+	go awaitInto(ctx, cancel, chB, &b, errCh)
+
+	if err := firstErr(errCh, 2); err != nil {
+		var (
+			zeroA A
+			zeroB B
+		)
+
+		return zeroA, zeroB, err
+	}
+
+	return a, b, nil
+}
+
+// Await3 concurrently awaits values of the chA, chB and chC channels of different types like Await2.
+func Await3[A, B, C any](ctx context.Context, chA <-chan Option[A], chB <-chan Option[B], chC <-chan Option[C]) (A, B, C, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		a A
+		b B
+		c C
+	)
+
+	errCh := make(chan error, 3)
+
+	go awaitInto(ctx, cancel, chA, &a, errCh)
+	go awaitInto(ctx, cancel, chB, &b, errCh)
+	go awaitInto(ctx, cancel, chC, &c, errCh)
+
+	if err := firstErr(errCh, 3); err != nil {
+		var (
+			zeroA A
+			zeroB B
+			zeroC C
+		)
+
+		return zeroA, zeroB, zeroC, err
+	}
+
+	return a, b, c, nil
+}
+
+// awaitInto awaits the ch channel into dst and sends the error to errCh. The error cancels the other waits
+// only after it's sent, so it's received before errors caused by the cancellation.
+func awaitInto[T any](ctx context.Context, cancel context.CancelFunc, ch <-chan Option[T], dst *T, errCh chan<- error) {
+	value, err := Await(ctx, ch)
+	*dst = value
+
+	errCh <- err
+
+	if err != nil {
+		cancel()
+	}
+}
+
+// firstErr receives n errors of errCh and returns the first non-nil one.
+func firstErr(errCh <-chan error, n int) (first error) {
+	for i := 0; i < n; i++ {
+		if err := <-errCh; err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// AwaitAllSettled awaits one option from each of chans and returns them in the order of chans.
+// It never fails fast: errors are returned as options. Channels interrupted by closed context get ctx error.
+func AwaitAllSettled[T any](ctx context.Context, chans ...<-chan Option[T]) []Option[T] {
+	opts := make([]Option[T], len(chans))
+
+	var wg sync.WaitGroup
+
+	wg.Add(len(chans))
+
+	for i, ch := range chans {
+		go func() {
+			defer wg.Done()
+
+			value, err := Await(ctx, ch)
+			opts[i] = Option[T]{value: value, err: err}
+		}()
+	}
+
+	wg.Wait()
+
+	return opts
+}
+
+// AwaitAny awaits the first value or error received from any of chans and returns it with the index of its channel.
+// The losers are drained in background until they are closed or ctx is done, so their producers are not blocked forever.
+// If ctx is done before any of chans produced, the returned index is -1.
+func AwaitAny[T any](ctx context.Context, chans ...<-chan Option[T]) (value T, index int, err error) {
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i     int
+		value T
+		err   error
+	}
+
+	resCh := make(chan result)
+
+	for i, ch := range chans {
+		go func(i int, ch <-chan Option[T]) {
+			value, err := Await(raceCtx, ch)
+
+			select {
+			case resCh <- result{i: i, value: value, err: err}:
+			case <-raceCtx.Done():
+				drain(ctx, ch)
+			}
+		}(i, ch)
+	}
+
+	select {
+	case <-ctx.Done():
+		return value, -1, ctx.Err()
+
+	case res := <-resCh:
+		return res.value, res.i, res.err
+	}
+}
+
+// drain abandons the ch channel, then reads and discards its values until it is closed or ctx is done.
+func drain[T any](ctx context.Context, ch <-chan Option[T]) {
+	Abandon(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// AwaitTimeout reads channel ch like Await, but waits no longer than d and returns ErrAwaitTimeout when d expires.
+// The task writing to ch is not stopped by timeout and keeps running. Having no context, it uses the system clock.
+// The failure of the task kept by its scope is returned like Await does.
+func AwaitTimeout[T any](ch <-chan Option[T], d time.Duration) (value T, err error) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return value, ErrAwaitTimeout
+
+	case opt, ok := <-ch:
+		if !ok {
+			if opt, ok = keptFailure(ch); !ok {
+				return value, ErrChannelClosed
+			}
+		}
+
+		return opt.Value(), opt.Err()
+	}
+}
+
+// Drain reads the options left in the ch channel, e.g. buffered by the task before cancellation,
+// until it is closed or timeout expires, and returns them. Errors are returned as options too,
+// including the failure of the task kept by its scope when the channel had no room for it.
+// Zero or less timeout reads only the options already buffered. Having no context, it uses the system clock.
+func Drain[T any](ch <-chan Option[T], timeout time.Duration) []Option[T] {
+	var opts []Option[T]
+
+	if timeout <= 0 {
+		for {
+			select {
+			case opt, ok := <-ch:
+				if !ok {
+					return drained(opts, ch)
+				}
+
+				opts = append(opts, opt)
+
+			default:
+				return opts
+			}
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return opts
+
+		case opt, ok := <-ch:
+			if !ok {
+				return drained(opts, ch)
+			}
+
+			opts = append(opts, opt)
+		}
+	}
+}
+
+// drained appends the failure of the closed ch channel's task kept by its scope to opts, see Drain.
+func drained[T any](opts []Option[T], ch <-chan Option[T]) []Option[T] {
+	if opt, ok := keptFailure(ch); ok {
+		opts = append(opts, opt)
+	}
+
+	return opts
+}
+
+// Collect reads the ch channel until it is closed and returns all values.
+// It stops at the first error and returns it, the rest of the channel is drained in background.
+// Partial value of the error option is kept, see MakeValueErr. Can be interrupted by closed context.
+func Collect[T any](ctx context.Context, ch <-chan Option[T]) ([]T, error) {
+	var values []T
+
+	for {
+		opt, ok, err := recv(ctx, ch)
+		if err != nil {
+			Abandon(ch)
+
+			return values, err
+		}
+
+		if !ok {
+			return values, nil
+		}
+
+		if err := opt.Err(); err != nil {
+			if opt.IsPartial() {
+				values = append(values, opt.Value())
+			}
+
+			go drain(ctx, ch)
+
+			return values, err
+		}
+
+		values = append(values, opt.Value())
+	}
+}
+
+// CollectMap reads the ch channel of keyed values until it is closed like Collect and returns them as map.
+func CollectMap[K comparable, V any](ctx context.Context, ch <-chan Option[KV[K, V]]) (map[K]V, error) {
+	values, err := Collect(ctx, ch)
+
+	m := make(map[K]V, len(values))
+	for _, kv := range values {
+		m[kv.Key] = kv.Value
+	}
+
+	return m, err
+}
+
+// CollectAll reads the ch channel until it is closed like Collect, but doesn't stop on errors.
+// It returns all values including partial ones and all errors joined by errors.Join.
+func CollectAll[T any](ctx context.Context, ch <-chan Option[T]) ([]T, error) {
+	var (
+		values []T
+		errs   []error
+	)
+
+	for {
+		opt, ok, err := recv(ctx, ch)
+		if err != nil {
+			Abandon(ch)
+
+			return values, errors.Join(append(errs, err)...)
+		}
+
+		if !ok {
+			return values, errors.Join(errs...)
+		}
+
+		if err := opt.Err(); err != nil {
+			errs = append(errs, err)
+
+			if !opt.IsPartial() {
+				continue
+			}
+		}
+
+		values = append(values, opt.Value())
+	}
+}
+
+// Reduce reads the ch channel until it is closed and folds its values by f starting from init.
+// It stops at the first error of the channel or f and returns it with the accumulator, the rest of the channel
+// is drained in background. Can be interrupted by closed context.
+func Reduce[T, A any](ctx context.Context, ch <-chan Option[T], init A, f func(A, T) (A, error)) (A, error) {
+	acc := init
+
+	for {
+		opt, ok, err := recv(ctx, ch)
+		if err != nil {
+			Abandon(ch)
+
+			return acc, err
+		}
+
+		if !ok {
+			return acc, nil
+		}
+
+		err = opt.Err()
+		if err == nil {
+			acc, err = f(acc, opt.Value())
+		}
+
+		if err != nil {
+			go drain(ctx, ch)
+
+			return acc, err
+		}
+	}
+}
+
+// AwaitN reads the ch channel until n values are received and returns them, e.g. for quorum reads over the output of Group.
+// Errors of the channel are skipped. If the channel is closed before n values, the received values are returned
+// with ErrChannelClosed joined with the skipped errors. The rest of the channel is abandoned and drained
+// in background, so only its producer started by GoLinked is cancelled, see Abandon. Other producers keep running
+// until their context is done. Can be interrupted by closed context.
+func AwaitN[T any](ctx context.Context, ch <-chan Option[T], n int) ([]T, error) {
+	var (
+		values = make([]T, 0, max(n, 0))
+		errs   []error
+	)
+
+	for len(values) < n {
+		opt, ok, err := recv(ctx, ch)
+		if err != nil {
+			Abandon(ch)
+
+			return values, err
+		}
+
+		if !ok {
+			return values, errors.Join(append([]error{ErrChannelClosed}, errs...)...)
+		}
+
+		if err := opt.Err(); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		values = append(values, opt.Value())
+	}
+
+	Abandon(ch)
+
+	go drain(ctx, ch)
+
+	return values, nil
+}
+
+// AwaitProgress reads the ch channel until it is closed, calls onItem for every value and returns the last value.
+// It stops at the first error and returns it with the last value, the rest of the channel is drained in background.
+// If the channel is closed without values, ErrChannelClosed is returned. Can be interrupted by closed context.
+func AwaitProgress[T any](ctx context.Context, ch <-chan Option[T], onItem func(T)) (last T, err error) {
+	received := false
+
+	for {
+		opt, ok, err := recv(ctx, ch)
+		if err != nil {
+			Abandon(ch)
+
+			return last, err
+		}
+
+		if !ok {
+			if !received {
+				return last, ErrChannelClosed
+			}
+
+			return last, nil
+		}
+
+		if err := opt.Err(); err != nil {
+			go drain(ctx, ch)
+
+			return last, err
+		}
+
+		last, received = opt.Value(), true
+
+		onItem(last)
+	}
+}
+
+// AwaitClose reads and discards values of the ch channel until it is closed, e.g. to wait for the side effects
+// of the producer. It returns the first error of the channel, the rest of the channel is drained in background.
+// Can be interrupted by closed context.
+func AwaitClose[T any](ctx context.Context, ch <-chan Option[T]) error {
+	for {
+		opt, ok, err := recv(ctx, ch)
+		if err != nil {
+			Abandon(ch)
+
+			return err
+		}
+
+		if !ok {
+			return nil
+		}
+
+		if err := opt.Err(); err != nil {
+			go drain(ctx, ch)
+
+			return err
+		}
+	}
+}
+
+// Result reads and discards options of the ch channel until it is closed and returns the final status of the task:
+// the error returned by the task function or its recovered panic, see Option.IsFinal, or nil if the task succeeded.
+// Errors sent to the channel by the function itself are skipped.
+// Can be interrupted by closed context.
+func Result[T any](ctx context.Context, ch <-chan Option[T]) error {
+	for {
+		opt, ok, err := recv(ctx, ch)
+		if err != nil {
+			Abandon(ch)
+
+			return err
+		}
+
+		if !ok {
+			return nil
+		}
+
+		if opt.IsFinal() {
+			go drain(ctx, ch)
+
+			return opt.Err()
+		}
+	}
+}
+
+// AwaitOr reads channel ch like Await, but returns fallback instead of any error:
+// the error option, the channel closed without value or the closed context.
+func AwaitOr[T any](ctx context.Context, ch <-chan Option[T], fallback T) T {
+	value, err := Await(ctx, ch)
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// AwaitStop reads channel ch like Await. If it's interrupted by closed context, AwaitStop cancels the ctx's scope,
+// abandons the channel and drains it until it's closed, so it returns only after the producer has exited.
+// The producer must honor cancellation, otherwise AwaitStop is blocked until the producer returns.
+func AwaitStop[T any](ctx context.Context, ch <-chan Option[T]) (value T, err error) {
+	value, err = Await(ctx, ch)
+	if err == nil || ctx.Err() == nil {
+		return value, err
+	}
+
+	if scope := scopeFrom(ctx); scope != nil {
+		scope.Cancel()
+	}
+
+	Abandon(ch)
+
+	for range ch {
+	}
+
+	return value, err
+}
@@ -0,0 +1,792 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestAwaitAll_Values(t *testing.T) {
+	ctx := context.Background()
+
+	chans := make([]<-chan async.Option[int], 0, 3)
+
+	for i := 0; i < 3; i++ {
+		value := i
+
+		chans = append(chans, async.Go(
+			ctx,
+			func(ch chan<- async.Option[int]) error {
+				// Finish in reverse order.
+				<-time.After(time.Duration(3-value) * 10 * time.Millisecond)
+				ch <- async.MakeValue(value)
+
+				return nil
+			},
+		))
+	}
+
+	values, err := async.AwaitAll(ctx, chans...)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	for i, v := range values {
+		if v != i {
+			t.Fail()
+
+			return
+		}
+	}
+}
+
+func TestAwaitAll_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	slow := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			<-time.After(10 * time.Second)
+			ch <- async.MakeValue(1)
+
+			return nil
+		},
+	)
+
+	failed := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeErr[int](testErr)
+
+			return nil
+		},
+	)
+
+	_, err := async.AwaitAll(ctx, slow, failed)
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestAwaitAny(t *testing.T) {
+	ctx := context.Background()
+
+	slow := async.Go(
+		ctx,
+		func(ch chan<- async.Option[string]) error {
+			<-time.After(10 * time.Second)
+			ch <- async.MakeValue("slow")
+
+			return nil
+		},
+	)
+
+	fast := async.Go(
+		ctx,
+		func(ch chan<- async.Option[string]) error {
+			ch <- async.MakeValue("fast")
+
+			return nil
+		},
+	)
+
+	v, i, err := async.AwaitAny(ctx, slow, fast)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v != "fast" || i != 1 {
+		t.Fail()
+	}
+}
+
+func TestAwaitAny_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			<-time.After(10 * time.Second)
+			ch <- async.MakeValue(1)
+
+			return nil
+		},
+	)
+
+	go func() {
+		<-time.After(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, _, err := async.AwaitAny(ctx, ch)
+	if !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+}
+
+func TestAwaitTimeout(t *testing.T) {
+	ch := async.Go(
+		context.Background(),
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+
+			return nil
+		},
+	)
+
+	v, err := async.AwaitTimeout(ch, time.Second)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v != 1 {
+		t.Fail()
+	}
+}
+
+func TestAwaitTimeout_Expired(t *testing.T) {
+	ch := async.Go(
+		context.Background(),
+		func(ch chan<- async.Option[int]) error {
+			<-time.After(10 * time.Second)
+			ch <- async.MakeValue(1)
+
+			return nil
+		},
+	)
+
+	_, err := async.AwaitTimeout(ch, 50*time.Millisecond)
+	if !errors.Is(err, async.ErrAwaitTimeout) {
+		t.Error(err)
+	}
+}
+
+func TestAwaitTimeout_Failure(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background())
+
+	ch := async.Go(scope.Context(), func(ch chan<- async.Option[int]) error { return testErr })
+
+	// Nobody reads the channel, so the failure is kept by the scope.
+	if err := scope.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+
+		return
+	}
+
+	if _, err := async.AwaitTimeout(ch, time.Second); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	const testN = 3
+
+	testErr := errors.New("test error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := async.GoCtx(
+		ctx,
+		func(ctx context.Context, ch chan<- async.Option[int]) error {
+			for i := 0; i < testN; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			<-ctx.Done()
+
+			return testErr
+		},
+		testN+1,
+	)
+
+	<-time.After(10 * time.Millisecond)
+
+	cancel()
+
+	opts := async.Drain(ch, time.Second)
+	if len(opts) != testN+1 {
+		t.Error(opts)
+
+		return
+	}
+
+	for i, opt := range opts[:testN] {
+		if opt.Value() != i || opt.Err() != nil {
+			t.Error(i, opt)
+		}
+	}
+
+	if !errors.Is(opts[testN].Err(), testErr) {
+		t.Error(opts[testN].Err())
+	}
+}
+
+func TestDrain_Failure(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background())
+
+	ch := async.Go(
+		scope.Context(),
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+
+			return testErr
+		},
+		1,
+	)
+
+	// The buffer is full, so the failure is kept by the scope.
+	if err := scope.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+
+		return
+	}
+
+	opts := async.Drain(ch, time.Second)
+	if len(opts) != 2 || opts[0].Value() != 1 || !errors.Is(opts[1].Err(), testErr) {
+		t.Error(opts)
+	}
+}
+
+func TestDrain_Timeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	ch := async.Go(
+		context.Background(),
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+
+			<-block
+
+			return nil
+		},
+		1,
+	)
+
+	<-time.After(10 * time.Millisecond)
+
+	if opts := async.Drain(ch, 0); len(opts) != 1 || opts[0].Value() != 1 {
+		t.Error(opts)
+
+		return
+	}
+
+	if opts := async.Drain(ch, 20*time.Millisecond); len(opts) != 0 {
+		t.Error(opts)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	const testN = 10
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 0; i < testN; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	values, err := async.Collect(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(values) != testN {
+		t.Error(values)
+
+		return
+	}
+
+	for i, v := range values {
+		if v != i {
+			t.Error(values)
+
+			return
+		}
+	}
+}
+
+func TestCollect_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+			ch <- async.MakeErr[int](testErr)
+			ch <- async.MakeValue(2)
+
+			return nil
+		},
+	)
+
+	values, err := async.Collect(ctx, ch)
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if len(values) != 1 {
+		t.Error(values)
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	errA := errors.New("error A")
+	errB := errors.New("error B")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+			ch <- async.MakeErr[int](errA)
+			ch <- async.MakeValue(2)
+			ch <- async.MakeErr[int](errB)
+
+			return nil
+		},
+	)
+
+	values, err := async.CollectAll(ctx, ch)
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Error(err)
+	}
+
+	if len(values) != 2 {
+		t.Error(values)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	const testN = 5
+
+	ctx := context.Background()
+
+	ch := async.Group(
+		ctx,
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				ch <- async.MakeValue(i + 1)
+
+				return nil
+			}
+		},
+		testN,
+	)
+
+	sum, err := async.Reduce(ctx, ch, 0, func(acc, v int) (int, error) { return acc + v, nil })
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if sum != 15 {
+		t.Error(sum)
+	}
+}
+
+func TestReduce_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 3; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	acc, err := async.Reduce(ctx, ch, 0, func(acc, v int) (int, error) {
+		if v == 2 {
+			return acc, testErr
+		}
+
+		return acc + v, nil
+	})
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if acc != 1 {
+		t.Error(acc)
+	}
+}
+
+func TestAwaitAllSettled(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ok := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			<-time.After(10 * time.Millisecond)
+			ch <- async.MakeValue(1)
+
+			return nil
+		},
+	)
+
+	failed := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeErr[int](testErr)
+
+			return nil
+		},
+	)
+
+	opts := async.AwaitAllSettled(ctx, ok, failed)
+	if len(opts) != 2 {
+		t.Error(opts)
+
+		return
+	}
+
+	if opts[0].Err() != nil || opts[0].Value() != 1 {
+		t.Error(opts[0])
+	}
+
+	if !errors.Is(opts[1].Err(), testErr) {
+		t.Error(opts[1])
+	}
+}
+
+func TestAwaitStop(t *testing.T) {
+	scopeCtx, cancelScope := async.With(context.Background())
+	defer cancelScope()
+
+	var exited atomic.Bool
+
+	ch := async.GoCtx(
+		scopeCtx,
+		func(ctx context.Context, ch chan<- async.Option[int]) error {
+			defer exited.Store(true)
+
+			<-ctx.Done()
+			<-time.After(20 * time.Millisecond)
+
+			return nil
+		},
+	)
+
+	ctx, cancel := context.WithCancel(scopeCtx)
+	cancel()
+
+	_, err := async.AwaitStop(ctx, ch)
+	if !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+
+	if !exited.Load() {
+		t.Error("producer is still running")
+	}
+}
+
+func TestAwaitN(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Group(
+		ctx,
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				switch i {
+				case 0:
+					ch <- async.MakeErr[int](testErr)
+				case 1:
+					<-time.After(10 * time.Second)
+					ch <- async.MakeValue(i)
+				default:
+					ch <- async.MakeValue(i)
+				}
+
+				return nil
+			}
+		},
+		4,
+	)
+
+	values, err := async.AwaitN(ctx, ch, 2)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(values) != 2 {
+		t.Error(values)
+	}
+}
+
+func TestAwaitN_Cancel(t *testing.T) {
+	ctx := context.Background()
+
+	stopped := make(chan struct{})
+
+	ch := async.GoLinked(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+		defer close(stopped)
+
+		for i := 0; ; i++ {
+			if err := async.TrySend(ctx, ch, i); err != nil {
+				return nil
+			}
+		}
+	})
+
+	values, err := async.AwaitN(ctx, ch, 2)
+	if err != nil || len(values) != 2 {
+		t.Error(values, err)
+
+		return
+	}
+
+	// The producer of the rest observes the cancellation.
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("producer is not cancelled")
+	}
+}
+
+func TestAwaitN_NotEnough(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+			ch <- async.MakeErr[int](testErr)
+
+			return nil
+		},
+	)
+
+	values, err := async.AwaitN(ctx, ch, 2)
+	if !errors.Is(err, async.ErrChannelClosed) || !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if len(values) != 1 {
+		t.Error(values)
+	}
+}
+
+func TestAwaitProgress(t *testing.T) {
+	const testN = 5
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= testN; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	var progress []int
+
+	last, err := async.AwaitProgress(ctx, ch, func(v int) { progress = append(progress, v) })
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if last != testN || len(progress) != testN {
+		t.Error(last, progress)
+	}
+}
+
+func TestAwaitProgress_Empty(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.Go(ctx, func(ch chan<- async.Option[int]) error { return nil })
+
+	_, err := async.AwaitProgress(ctx, ch, func(int) {})
+	if !errors.Is(err, async.ErrChannelClosed) {
+		t.Error(err)
+	}
+}
+
+func TestAwait2(t *testing.T) {
+	ctx := context.Background()
+
+	user := async.GoValue(ctx, func(ctx context.Context) (string, error) { return "user", nil })
+	orders := async.GoValue(ctx, func(ctx context.Context) (int, error) { return 3, nil })
+
+	u, n, err := async.Await2(ctx, user, orders)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if u != "user" || n != 3 {
+		t.Error(u, n)
+	}
+}
+
+func TestAwait3_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	a := async.GoValue(ctx, func(ctx context.Context) (int, error) { return 1, nil })
+	b := async.GoValue(ctx, func(ctx context.Context) (string, error) { return "", testErr })
+	c := async.Go(ctx, func(ch chan<- async.Option[bool]) error {
+		<-time.After(10 * time.Second)
+		ch <- async.MakeValue(true)
+
+		return nil
+	})
+
+	_, _, _, err := async.Await3(ctx, a, b, c)
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestAwaitClose(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	var flushed atomic.Bool
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+			flushed.Store(true)
+
+			return nil
+		},
+	)
+
+	if err := async.AwaitClose(ctx, ch); err != nil || !flushed.Load() {
+		t.Error(err)
+	}
+
+	ch = async.Go(ctx, func(ch chan<- async.Option[int]) error { return testErr }, 1)
+
+	if err := async.AwaitClose(ctx, ch); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestResult(t *testing.T) {
+	elemErr := errors.New("element error")
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeErr[int](elemErr)
+
+			return testErr
+		},
+		2,
+	)
+
+	opt := <-ch
+	if !errors.Is(opt.Err(), elemErr) || opt.IsFinal() {
+		t.Error(opt.Err())
+
+		return
+	}
+
+	if err := async.Result(ctx, ch); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	ch = async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeErr[int](elemErr)
+
+			return nil
+		},
+	)
+
+	if err := async.Result(ctx, ch); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResult_FailedEarly(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(ctx, func(ch chan<- async.Option[int]) error { return testErr })
+
+	// The task fails before the consumer comes, its error waits for it.
+	time.Sleep(10 * time.Millisecond)
+
+	if err := async.Result(ctx, ch); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestAwaitOr(t *testing.T) {
+	ctx := context.Background()
+
+	value := async.GoValue(ctx, func(ctx context.Context) (string, error) { return "avatar", nil })
+	failed := async.GoValue(ctx, func(ctx context.Context) (string, error) { return "", errors.New("test error") })
+	empty := async.Go(ctx, func(ch chan<- async.Option[string]) error { return nil })
+
+	if v := async.AwaitOr(ctx, value, "default"); v != "avatar" {
+		t.Error(v)
+	}
+
+	if v := async.AwaitOr(ctx, failed, "default"); v != "default" {
+		t.Error(v)
+	}
+
+	if v := async.AwaitOr(ctx, empty, "default"); v != "default" {
+		t.Error(v)
+	}
+}
@@ -0,0 +1,191 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit is open")
+
+// BreakerPolicy configures CircuitBreaker.
+type BreakerPolicy struct {
+	// Window is the number of the last calls the error rate is measured over. Zero or less means 10 calls.
+	Window int
+	// ErrorRate trips the breaker when the share of failed calls of the full window reaches it,
+	// it is ranged from 0 to 1. Zero or less means 0.5.
+	ErrorRate float64
+	// Cooldown is the time the tripped breaker rejects calls before it lets a trial call in.
+	Cooldown time.Duration
+}
+
+// BreakerState is the state of CircuitBreaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets calls in and measures their error rate.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects calls with ErrCircuitOpen until the cooldown is over.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial call in: its success closes the breaker, its failure opens it again.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker rejects calls of the protected functions quickly after too many of them failed, see Protect.
+// It's shared by all functions protected by it.
+type CircuitBreaker struct {
+	policy BreakerPolicy
+	clock  Clock
+
+	mu       sync.Mutex
+	state    BreakerState
+	outcomes []bool
+	next     int
+	failed   int
+	opened   time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker returns the closed breaker. The cooldown is measured by the clock of ctx, see WithClock.
+func NewCircuitBreaker(ctx context.Context, policy BreakerPolicy) *CircuitBreaker {
+	if policy.Window < 1 {
+		policy.Window = 10
+	}
+
+	if policy.ErrorRate <= 0 {
+		policy.ErrorRate = 0.5
+	}
+
+	return &CircuitBreaker{policy: policy, clock: clockFrom(ctx)}
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && !b.clock.Now().Before(b.opened.Add(b.policy.Cooldown)) {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+// Protect wraps f to be called only if the breaker lets it in, otherwise the wrapped function fails with ErrCircuitOpen
+// without calling f. Errors and panics of f are counted as failures.
+func Protect[T any](b *CircuitBreaker, f Func[T]) Func[T] {
+	return func(ch chan<- Option[T]) error {
+		trial, err := b.allow()
+		if err != nil {
+			return err
+		}
+
+		failed := true
+
+		defer func() { b.record(trial, failed) }()
+
+		err = f(ch)
+		failed = err != nil
+
+		return err
+	}
+}
+
+// Breaker returns the wrapper protecting functions by the new breaker shared by all of them, see Protect.
+func Breaker[T any](ctx context.Context, policy BreakerPolicy) func(Func[T]) Func[T] {
+	b := NewCircuitBreaker(ctx, policy)
+
+	return func(f Func[T]) Func[T] { return Protect(b, f) }
+}
+
+// allow reports whether the call can be made, trial is true for the trial call of the half-open breaker.
+func (b *CircuitBreaker) allow() (trial bool, _ error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		return false, nil
+
+	case BreakerOpen:
+		if b.clock.Now().Before(b.opened.Add(b.policy.Cooldown)) {
+			return false, ErrCircuitOpen
+		}
+
+		b.state = BreakerHalfOpen
+	}
+
+	if b.trial {
+		return false, ErrCircuitOpen
+	}
+
+	b.trial = true
+
+	return true, nil
+}
+
+func (b *CircuitBreaker) record(trial, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.trial = false
+
+		if failed {
+			b.open()
+		} else {
+			b.reset()
+		}
+
+		return
+	}
+
+	if b.state != BreakerClosed {
+		return
+	}
+
+	if len(b.outcomes) < b.policy.Window {
+		b.outcomes = append(b.outcomes, failed)
+	} else {
+		if b.outcomes[b.next] {
+			b.failed--
+		}
+
+		b.outcomes[b.next] = failed
+		b.next = (b.next + 1) % b.policy.Window
+	}
+
+	if failed {
+		b.failed++
+	}
+
+	if len(b.outcomes) == b.policy.Window && float64(b.failed) >= b.policy.ErrorRate*float64(b.policy.Window) {
+		b.open()
+	}
+}
+
+func (b *CircuitBreaker) open() {
+	b.state = BreakerOpen
+	b.opened = b.clock.Now()
+}
+
+func (b *CircuitBreaker) reset() {
+	b.state = BreakerClosed
+	b.outcomes = b.outcomes[:0]
+	b.next = 0
+	b.failed = 0
+}
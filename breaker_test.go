@@ -0,0 +1,93 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+	"github.com/WinPooh32/async/v2/asynctest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	testErr := errors.New("test error")
+
+	clock := asynctest.NewFakeClock(time.Unix(0, 0))
+
+	ctx := async.WithClock(clock)(context.Background())
+
+	b := async.NewCircuitBreaker(ctx, async.BreakerPolicy{Window: 4, ErrorRate: 0.5, Cooldown: time.Second})
+
+	var calls int
+
+	call := func(err error) error {
+		f := async.Protect(b, func(ch chan<- async.Option[int]) error {
+			calls++
+
+			return err
+		})
+
+		return async.Result(ctx, async.Go(ctx, f, 1))
+	}
+
+	for _, err := range []error{nil, testErr, nil, testErr} {
+		if got := call(err); !errors.Is(got, err) {
+			t.Error(got)
+
+			return
+		}
+	}
+
+	if b.State() != async.BreakerOpen {
+		t.Error(b.State())
+
+		return
+	}
+
+	// The open breaker rejects calls without calling the functions.
+	if err := call(nil); !errors.Is(err, async.ErrCircuitOpen) || calls != 4 {
+		t.Error(err, calls)
+
+		return
+	}
+
+	clock.Advance(time.Second)
+
+	if b.State() != async.BreakerHalfOpen {
+		t.Error(b.State())
+
+		return
+	}
+
+	// The failed trial opens the breaker again.
+	if err := call(testErr); !errors.Is(err, testErr) || b.State() != async.BreakerOpen {
+		t.Error(err, b.State())
+
+		return
+	}
+
+	clock.Advance(time.Second)
+
+	if err := call(nil); err != nil || b.State() != async.BreakerClosed {
+		t.Error(err, b.State())
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	protect := async.Breaker[int](ctx, async.BreakerPolicy{Window: 1, Cooldown: time.Hour})
+
+	f := protect(func(ch chan<- async.Option[int]) error { return testErr })
+
+	if err := async.Result(ctx, async.Go(ctx, f, 1)); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if err := async.Result(ctx, async.Go(ctx, f, 1)); !errors.Is(err, async.ErrCircuitOpen) {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,196 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrBroadcastClosed = errors.New("broadcast is closed")
+
+// Broadcast passes every published option to all of its subscribers.
+// Subscribers which don't keep up are handled by the overflow policy of the broadcast.
+type Broadcast[T any] struct {
+	policy   OverflowPolicy
+	capacity int
+
+	mu     sync.Mutex
+	subs   map[<-chan Option[T]]*subscriber[T]
+	closed bool
+
+	closing chan struct{}
+	once    sync.Once
+}
+
+type subscriber[T any] struct {
+	ch   chan Option[T]
+	done chan struct{}
+	once sync.Once
+	stop func() bool
+}
+
+// NewBroadcast returns the broadcast whose subscribers get channels of capacity.
+// When the channel of a subscriber is full, policy decides what to do with the new option:
+// OverflowBlock makes Publish wait for the subscriber, OverflowDropNewest and OverflowDropOldest drop an option
+// of the subscriber, OverflowError drops the new option and makes Publish fail with ErrChannelFull.
+// Channels of the broadcast with drop policies have capacity of at least 1.
+func NewBroadcast[T any](policy OverflowPolicy, capacity int) *Broadcast[T] {
+	if policy != OverflowBlock {
+		capacity = max(capacity, 1)
+	}
+
+	return &Broadcast[T]{
+		policy:   policy,
+		capacity: max(capacity, 0),
+		subs:     make(map[<-chan Option[T]]*subscriber[T]),
+		closing:  make(chan struct{}),
+	}
+}
+
+// Subscribe returns the new channel receiving options published after the call.
+// The channel is closed by Unsubscribe, Close or when ctx is done.
+func (b *Broadcast[T]) Subscribe(ctx context.Context) <-chan Option[T] {
+	sub := &subscriber[T]{
+		ch:   make(chan Option[T], b.capacity),
+		done: make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(sub.ch)
+
+		return sub.ch
+	}
+
+	b.subs[sub.ch] = sub
+
+	sub.stop = context.AfterFunc(ctx, func() { b.Unsubscribe(sub.ch) })
+
+	return sub.ch
+}
+
+// Unsubscribe closes the ch channel returned by Subscribe. It does nothing for other channels.
+func (b *Broadcast[T]) Unsubscribe(ch <-chan Option[T]) {
+	b.mu.Lock()
+	sub := b.subs[ch]
+	b.mu.Unlock()
+
+	if sub == nil {
+		return
+	}
+
+	// Publish blocked on the subscriber releases the lock.
+	sub.once.Do(func() { close(sub.done) })
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		sub.stop()
+		close(sub.ch)
+	}
+}
+
+// Publish passes value to all subscribers.
+func (b *Broadcast[T]) Publish(ctx context.Context, value T) error {
+	return b.publish(ctx, MakeValue(value))
+}
+
+// PublishErr passes err to all subscribers.
+func (b *Broadcast[T]) PublishErr(ctx context.Context, err error) error {
+	return b.publish(ctx, MakeErr[T](err))
+}
+
+// PublishFrom publishes all options of the in channel, e.g. of a task started by Go, until it is closed.
+// Interrupted by closed context, the rest of the in channel is drained in background then.
+func (b *Broadcast[T]) PublishFrom(ctx context.Context, in <-chan Option[T]) error {
+	for {
+		opt, ok, err := recv(ctx, in)
+		if err != nil {
+			go drain(ctx, in)
+
+			return err
+		}
+
+		if !ok {
+			return nil
+		}
+
+		if err := b.publish(ctx, opt); err != nil && !errors.Is(err, ErrChannelFull) {
+			go drain(ctx, in)
+
+			return err
+		}
+	}
+}
+
+// Close closes channels of all subscribers, following Publish and Subscribe calls fail.
+func (b *Broadcast[T]) Close() {
+	// Publish blocked on a subscriber releases the lock.
+	b.once.Do(func() { close(b.closing) })
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	for ch, sub := range b.subs {
+		delete(b.subs, ch)
+		sub.stop()
+		close(sub.ch)
+	}
+}
+
+func (b *Broadcast[T]) publish(ctx context.Context, opt Option[T]) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBroadcastClosed
+	}
+
+	var full bool
+
+	for _, sub := range b.subs {
+		switch b.policy {
+		case OverflowBlock:
+			select {
+			case sub.ch <- opt:
+			case <-sub.done:
+			case <-b.closing:
+				return ErrBroadcastClosed
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+		case OverflowDropOldest:
+			for sent := false; !sent; {
+				select {
+				case sub.ch <- opt:
+					sent = true
+				default:
+					select {
+					case <-sub.ch:
+					default:
+					}
+				}
+			}
+
+		default:
+			select {
+			case sub.ch <- opt:
+			default:
+				full = true
+			}
+		}
+	}
+
+	if full && b.policy == OverflowError {
+		return ErrChannelFull
+	}
+
+	return nil
+}
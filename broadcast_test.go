@@ -0,0 +1,95 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestBroadcast(t *testing.T) {
+	const testN = 3
+
+	ctx := context.Background()
+
+	b := async.NewBroadcast[int](async.OverflowBlock, 0)
+
+	subs := make([]<-chan async.Option[int], testN)
+	for i := range subs {
+		subs[i] = b.Subscribe(ctx)
+	}
+
+	src := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		for i := 1; i <= 3; i++ {
+			ch <- async.MakeValue(i)
+		}
+
+		return nil
+	})
+
+	go func() {
+		defer b.Close()
+
+		if err := b.PublishFrom(ctx, src); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	counts := make(chan int, testN)
+
+	for _, sub := range subs {
+		go func() {
+			values, _ := async.Collect(ctx, sub)
+			counts <- len(values)
+		}()
+	}
+
+	for range subs {
+		if n := <-counts; n != 3 {
+			t.Error(n)
+		}
+	}
+
+	if err := b.Publish(ctx, 4); !errors.Is(err, async.ErrBroadcastClosed) {
+		t.Error(err)
+	}
+}
+
+func TestBroadcast_Drop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := async.NewBroadcast[int](async.OverflowDropOldest, 1)
+
+	slow := b.Subscribe(ctx)
+	other := b.Subscribe(context.Background())
+
+	for i := 1; i <= 3; i++ {
+		if err := b.Publish(ctx, i); err != nil {
+			t.Error(err)
+
+			return
+		}
+	}
+
+	if v := (<-slow).Value(); v != 3 {
+		t.Error(v)
+	}
+
+	cancel()
+
+	// The subscription ends with its context.
+	for range slow {
+	}
+
+	b.Unsubscribe(other)
+
+	if v := (<-other).Value(); v != 3 {
+		t.Error(v)
+	}
+
+	if _, ok := <-other; ok {
+		t.Fail()
+	}
+}
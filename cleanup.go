@@ -0,0 +1,66 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	contextKeyCleanup  contextKey = "cleanup"
+	contextKeyCleanups contextKey = "cleanups"
+)
+
+// cleanups of the task are called in reverse order after its function returns or panics.
+type cleanups struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// WithCleanup registers f to be called after the function of the task started by GoWith or Pool.SubmitWith
+// returns or panics, before its channel is closed. Tasks started by the function don't inherit f.
+func WithCleanup(f func()) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		fns, _ := ctx.Value(contextKeyCleanup).([]func())
+
+		return context.WithValue(ctx, contextKeyCleanup, append(fns[:len(fns):len(fns)], f))
+	}
+	return fn
+}
+
+// Defer registers f to be called after the function of the task owning ctx returns or panics,
+// before its channel is closed. Functions registered by the task are called in reverse order like deferred calls.
+// It does nothing if ctx doesn't belong to a task.
+func Defer(ctx context.Context, f func()) {
+	c, _ := ctx.Value(contextKeyCleanups).(*cleanups)
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.fns = append(c.fns, f)
+	c.mu.Unlock()
+}
+
+// withCleanups returns the context of the new task with cleanups set by WithCleanup, they aren't inherited by
+// the tasks started by it.
+func withCleanups(ctx context.Context) (context.Context, *cleanups) {
+	c := &cleanups{}
+
+	if fns, _ := ctx.Value(contextKeyCleanup).([]func()); len(fns) > 0 {
+		c.fns = fns
+		ctx = context.WithValue(ctx, contextKeyCleanup, nil)
+	}
+
+	return context.WithValue(ctx, contextKeyCleanups, c), c
+}
+
+func (c *cleanups) run() {
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		fns[i]()
+	}
+}
@@ -0,0 +1,66 @@
+package async_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestWithCleanup(t *testing.T) {
+	var calls []string
+
+	ctx := context.Background()
+
+	ch := async.GoWith(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			panic("something went wrong!")
+		},
+		async.WithCleanup(func() { calls = append(calls, "first") }),
+		async.WithCleanup(func() { calls = append(calls, "second") }),
+		async.WithCapacity(1),
+	)
+
+	for range ch {
+	}
+
+	// Cleanups are called before the channel is closed, in reverse order.
+	if !slices.Equal(calls, []string{"second", "first"}) {
+		t.Error(calls)
+	}
+}
+
+func TestDefer(t *testing.T) {
+	ctx := context.Background()
+
+	var released, inherited bool
+
+	ch := async.GoSender(ctx, func(ctx context.Context, s async.Sender[int]) error {
+		s.Defer(func() { released = true })
+
+		for range async.GoCtx(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+			return nil
+		}) {
+		}
+
+		// The cleanup belongs to the task which has registered it.
+		inherited = released
+
+		return s.Send(ctx, 1)
+	})
+
+	if _, err := async.Collect(ctx, ch); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if !released || inherited {
+		t.Error(released, inherited)
+	}
+
+	// Outside of a task, Defer does nothing.
+	async.Defer(ctx, func() { t.Fail() })
+}
@@ -0,0 +1,99 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+var contextKeyClock contextKey = "clock"
+
+// Clock is the source of time for the time-based functions of the package, see WithClock.
+// Tests can replace it with a fake clock advancing time deterministically.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the single event timer created by Clock, like time.Timer.
+// The channel of the timer created by AfterFunc is nil.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the periodic timer created by Clock, like time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock makes the time-based functions started with the context use clock instead of the system time:
+// timers, tickers, retries, timeouts, the watchdog, Debounce, Throttle, Batch, Memo and the times of the tasks.
+// Set for the scope by NewScope or With, it's used by all tasks of the scope.
+func WithClock(clock Clock) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyClock, clock)
+	}
+	return fn
+}
+
+func clockFrom(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(contextKeyClock).(Clock); ok && clock != nil {
+		return clock
+	}
+
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+func (systemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+// withTimeout returns the context done after d by the clock of ctx like context.WithTimeout.
+// Err of the expired context is context.DeadlineExceeded for any clock.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	clock := clockFrom(ctx)
+	if _, ok := clock.(systemClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	timer := clock.AfterFunc(d, func() { cancel(context.DeadlineExceeded) })
+
+	return timeoutCtx{ctx}, func() {
+		timer.Stop()
+		cancel(nil)
+	}
+}
+
+// timeoutCtx is the context expired by the timer of the clock.
+type timeoutCtx struct{ context.Context }
+
+func (ctx timeoutCtx) Err() error {
+	err := ctx.Context.Err()
+	if err != nil && context.Cause(ctx.Context) == context.DeadlineExceeded {
+		return context.DeadlineExceeded
+	}
+
+	return err
+}
+
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
@@ -0,0 +1,136 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	contextKeyTask  contextKey = "task"
+	contextKeyDebug contextKey = "debug"
+)
+
+// TaskExit is the way the task has exited, it's recorded in debug mode, see WithDebug.
+type TaskExit int
+
+const (
+	// ExitUnknown is the exit of the running task or the task out of debug mode.
+	ExitUnknown TaskExit = iota
+	// ExitNormal is the exit without error.
+	ExitNormal
+	// ExitFailed is the exit with error.
+	ExitFailed
+	// ExitPanicked is the exit by recovered panic.
+	ExitPanicked
+	// ExitCanceled is the exit with error of the closed context.
+	ExitCanceled
+	// ExitSwallowedCancel is the exit without the context's error after the context was closed.
+	ExitSwallowedCancel
+	// ExitAbandoned is the exit of the task whose channel was abandoned by the consumer, see Abandon.
+	ExitAbandoned
+)
+
+func (e TaskExit) String() string {
+	switch e {
+	case ExitNormal:
+		return "normal"
+	case ExitFailed:
+		return "failed"
+	case ExitPanicked:
+		return "panicked"
+	case ExitCanceled:
+		return "canceled"
+	case ExitSwallowedCancel:
+		return "swallowed cancel"
+	case ExitAbandoned:
+		return "abandoned"
+	default:
+		return "unknown"
+	}
+}
+
+// WithDebug makes the scope keep its done tasks and record the way they have exited, see Scope.Report.
+func WithDebug() OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyDebug, true)
+	}
+	return fn
+}
+
+// Report is the snapshot of the scope's tasks with their exits recorded in debug mode.
+type Report struct {
+	Tasks []TaskInfo
+	// Time is the time of the snapshot, durations of running tasks are measured up to it.
+	Time time.Time
+}
+
+// Count returns the number of tasks exited the exit way.
+func (r Report) Count(exit TaskExit) int {
+	n := 0
+
+	for _, task := range r.Tasks {
+		if task.Exit == exit {
+			n++
+		}
+	}
+
+	return n
+}
+
+// String formats the report as one line per task.
+func (r Report) String() string {
+	var b strings.Builder
+
+	for _, task := range r.Tasks {
+		duration := r.Time.Sub(task.Started)
+		if task.State == TaskDone {
+			duration = task.Finished.Sub(task.Started)
+		}
+
+		fmt.Fprintf(&b, "%q %s %s %s\n", task.Name, task.State, task.Exit, duration)
+	}
+
+	return b.String()
+}
+
+// Report returns the report of the scope's tasks. Done tasks and their exits are kept only if the scope
+// is created with WithDebug, otherwise the report has running tasks only, see Scope.Tasks.
+func (s *Scope) Report() Report {
+	return Report{Tasks: s.Tasks(), Time: clockFrom(s.ctx).Now()}
+}
+
+// recordExit records the exit of the task of ctx in debug mode.
+func recordExit(ctx context.Context, err error, panicked bool) {
+	if debug, _ := ctx.Value(contextKeyDebug).(bool); !debug {
+		return
+	}
+
+	task, _ := ctx.Value(contextKeyTask).(*TaskInfo)
+	scope := scopeFrom(ctx)
+
+	if task == nil || scope == nil {
+		return
+	}
+
+	exit := ExitNormal
+
+	switch {
+	case panicked:
+		exit = ExitPanicked
+	case errors.Is(context.Cause(ctx), errAbandoned):
+		exit = ExitAbandoned
+	case err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		exit = ExitCanceled
+	case err != nil:
+		exit = ExitFailed
+	case ctx.Err() != nil:
+		exit = ExitSwallowedCancel
+	}
+
+	scope.mu.Lock()
+	task.Exit = exit
+	scope.mu.Unlock()
+}
@@ -0,0 +1,61 @@
+package async_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestScope_Report(t *testing.T) {
+	scope := async.NewScope(context.Background(), async.WithDebug())
+
+	ctx := scope.Context()
+
+	for range async.Go(ctx, func(ch chan<- async.Option[int]) error { return nil }) {
+	}
+
+	abandoned := async.GoLinked(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	async.Abandon(abandoned)
+
+	for range abandoned {
+	}
+
+	async.GoCtx(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, 1)
+
+	async.GoCtx(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+		<-ctx.Done()
+
+		return nil
+	}, 1)
+
+	scope.Cancel()
+	scope.Wait()
+
+	report := scope.Report()
+
+	for exit, want := range map[async.TaskExit]int{
+		async.ExitNormal:          1,
+		async.ExitAbandoned:       1,
+		async.ExitCanceled:        1,
+		async.ExitSwallowedCancel: 1,
+	} {
+		if n := report.Count(exit); n != want {
+			t.Error(exit, n)
+		}
+	}
+
+	if lines := strings.Count(report.String(), "\n"); lines != 4 {
+		t.Error(report.String())
+	}
+}
@@ -0,0 +1,33 @@
+package async
+
+import (
+	"context"
+	"errors"
+)
+
+// IsPanic reports whether err is the recovered panic of a task, see PanicError.
+func IsPanic(err error) bool {
+	var panicErr *PanicError
+
+	return errors.As(err, &panicErr)
+}
+
+// IsTimeout reports whether err is caused by an expired timeout or deadline: ErrAwaitTimeout, ErrWaitTimeout,
+// ErrTaskTimeout, ErrTaskStuck of the watchdog or context.DeadlineExceeded.
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrAwaitTimeout) ||
+		errors.Is(err, ErrWaitTimeout) ||
+		errors.Is(err, ErrTaskTimeout) ||
+		errors.Is(err, ErrTaskStuck) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsCanceled reports whether err is caused by the cancelled context, e.g. the cancelled scope.
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// IsChannelClosed reports whether err is ErrChannelClosed, i.e. the channel is closed without the awaited value.
+func IsChannelClosed(err error) bool {
+	return errors.Is(err, ErrChannelClosed)
+}
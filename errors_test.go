@@ -0,0 +1,76 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestIsPanic(t *testing.T) {
+	ch := async.Go(context.Background(), func(ch chan<- async.Option[int]) error {
+		panic("test panic")
+	}, 1)
+
+	_, err := async.Await(context.Background(), ch)
+	if !async.IsPanic(err) || async.IsTimeout(err) || async.IsCanceled(err) || async.IsChannelClosed(err) {
+		t.Error(err)
+	}
+
+	if async.IsPanic(errors.New("test error")) {
+		t.Fail()
+	}
+}
+
+func TestIsTimeout(t *testing.T) {
+	ch := async.Go(context.Background(), func(ch chan<- async.Option[int]) error {
+		<-time.After(time.Second)
+
+		return nil
+	})
+
+	_, err := async.AwaitTimeout(ch, 10*time.Millisecond)
+	if !async.IsTimeout(err) {
+		t.Error(err)
+
+		return
+	}
+
+	f := async.Timeout(func(ctx context.Context, ch chan<- async.Option[int]) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, 10*time.Millisecond)
+
+	_, err = async.Await(context.Background(), async.GoCtx(context.Background(), f, 1))
+	if !async.IsTimeout(err) || async.IsPanic(err) {
+		t.Error(err)
+	}
+}
+
+func TestIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch := async.GoCtx(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+		return ctx.Err()
+	}, 1)
+
+	_, err := async.Await(context.Background(), ch)
+	if !async.IsCanceled(err) || async.IsTimeout(err) {
+		t.Error(err)
+	}
+}
+
+func TestIsChannelClosed(t *testing.T) {
+	ch := async.Go(context.Background(), func(ch chan<- async.Option[int]) error {
+		return nil
+	})
+
+	_, err := async.Await(context.Background(), ch)
+	if !async.IsChannelClosed(err) || async.IsCanceled(err) {
+		t.Error(err)
+	}
+}
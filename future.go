@@ -0,0 +1,74 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Future is a memoized result of the option channel.
+// It can be awaited multiple times from different goroutines.
+type Future[T any] struct {
+	ch   <-chan Option[T]
+	recv chan struct{}
+	done chan struct{}
+	opt  Option[T]
+}
+
+// NewFuture wraps the ch channel into the future.
+func NewFuture[T any](ch <-chan Option[T]) *Future[T] {
+	return &Future[T]{
+		ch:   ch,
+		recv: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+}
+
+// Await reads the first option from the wrapped channel and caches it.
+// Subsequent calls return the cached value and error.
+// Can be interrupted by closed context, in this case nothing is cached.
+func (f *Future[T]) Await(ctx context.Context) (value T, err error) {
+	select {
+	case <-f.done:
+		return f.opt.Value(), f.opt.Err()
+
+	case <-ctx.Done():
+		return value, ctx.Err()
+
+	case f.recv <- struct{}{}:
+		defer func() { <-f.recv }()
+	}
+
+	// The result could be cached while we were waiting for the receive permission.
+	select {
+	case <-f.done:
+		return f.opt.Value(), f.opt.Err()
+	default:
+	}
+
+	value, err = await(ctx, f.ch)
+	if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return value, err
+	}
+
+	f.opt = Option[T]{value: value, err: err}
+	close(f.done)
+
+	return value, err
+}
+
+// Lazy returns the function starting f at a new goroutine with ctx like Go on its first call only.
+// All calls await the first option of f and return it, both value and error are memoized like Future does.
+// A call interrupted by its closed context doesn't stop the computation.
+func Lazy[T any](ctx context.Context, f Func[T]) func(ctx context.Context) (T, error) {
+	var (
+		once   sync.Once
+		future *Future[T]
+	)
+
+	return func(callCtx context.Context) (T, error) {
+		once.Do(func() { future = NewFuture(Go(ctx, f, 1)) })
+
+		return future.Await(callCtx)
+	}
+}
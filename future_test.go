@@ -0,0 +1,128 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestFuture_Await(t *testing.T) {
+	const testValue = 1
+	const testAwaits = 10
+
+	ctx := context.Background()
+
+	future := async.NewFuture(async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(testValue)
+
+			return nil
+		},
+	))
+
+	var wg sync.WaitGroup
+
+	wg.Add(testAwaits)
+
+	for i := 0; i < testAwaits; i++ {
+		go func() {
+			defer wg.Done()
+
+			v, err := future.Await(ctx)
+			if err != nil {
+				t.Error(err)
+
+				return
+			}
+
+			if v != testValue {
+				t.Fail()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestFuture_CanceledContext(t *testing.T) {
+	const testValue = 1
+
+	future := async.NewFuture(async.Go(
+		context.Background(),
+		func(ch chan<- async.Option[int]) error {
+			<-time.After(100 * time.Millisecond)
+			ch <- async.MakeValue(testValue)
+
+			return nil
+		},
+	))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := future.Await(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Error(err)
+
+		return
+	}
+
+	// Canceled await must not be cached.
+	v, err := future.Await(context.Background())
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v != testValue {
+		t.Fail()
+	}
+}
+
+func TestLazy(t *testing.T) {
+	const testCalls = 10
+
+	ctx := context.Background()
+
+	var starts atomic.Int32
+
+	get := async.Lazy(ctx, func(ch chan<- async.Option[int]) error {
+		starts.Add(1)
+		ch <- async.MakeValue(42)
+
+		return nil
+	})
+
+	if starts.Load() != 0 {
+		t.Error(starts.Load())
+
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(testCalls)
+
+	for i := 0; i < testCalls; i++ {
+		go func() {
+			defer wg.Done()
+
+			if v, err := get(ctx); err != nil || v != 42 {
+				t.Error(v, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if starts.Load() != 1 {
+		t.Error(starts.Load())
+	}
+}
@@ -0,0 +1,240 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// GroupN runs g(i) functions in parallel like Group, but no more than limit functions are running at the same time.
+// The next function is started when one of the running functions finished and its output is passed to the channel.
+// When ctx is done, the rest of the functions are not started and the channel is closed after the started ones finish.
+// If limit is less than 1, GroupN is the same as Group.
+func GroupN[T any](ctx context.Context, g func(i int) Func[T], n, limit int, capacity ...int) <-chan Option[T] {
+	if limit < 1 {
+		return Group(ctx, g, n, capacity...)
+	}
+
+	ctx, prog := withProgress(ctx, n)
+
+	scope := scopeFrom(ctx)
+	ch := makeChan[T](capacity...)
+
+	fn := func(outCh chan<- Option[T]) error {
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		sem := make(chan struct{}, limit)
+
+		for i := 0; i < n; i++ {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+
+			inCh := goUpstream(withIndex(ctx, i), g(i), ch, 1)
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				defer prog.finish()
+
+				relay(scope, inCh, outCh)
+			}()
+		}
+
+		return nil
+	}
+
+	return goStage(withoutLimits(ctx), fn, ch)
+}
+
+// GroupOrdered runs g(i) functions in parallel like Group, but their output falls into the channel in index order:
+// all output of g(0) first, then output of g(1) and so on.
+func GroupOrdered[T any](ctx context.Context, g func(i int) Func[T], n int, capacity ...int) <-chan Option[T] {
+	ctx, prog := withProgress(ctx, n)
+
+	scope := scopeFrom(ctx)
+	ch := makeChan[T](capacity...)
+
+	fn := func(outCh chan<- Option[T]) error {
+		chans := make([]<-chan Option[T], n)
+
+		for i := 0; i < n; i++ {
+			chans[i] = goUpstream(withIndex(ctx, i), g(i), ch, 1)
+		}
+
+		for _, inCh := range chans {
+			relay(scope, inCh, outCh)
+
+			prog.finish()
+		}
+
+		return nil
+	}
+
+	return goStage(withoutLimits(ctx), fn, ch)
+}
+
+// GroupAll runs g(i) functions in parallel like Group, but errors don't stop the group.
+// Values fall into the channel as usual, while all errors sent or returned by the functions are collected
+// and joined by errors.Join into the last option of the channel. Returned errors and recovered panics
+// are wrapped into *TaskError with the index of the function like Group does, but they don't fail the scope.
+func GroupAll[T any](ctx context.Context, g func(i int) Func[T], n int, capacity ...int) <-chan Option[T] {
+	ctx, prog := withProgress(ctx, n)
+
+	scope := scopeFrom(ctx)
+
+	fn := func(outCh chan<- Option[T]) error {
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			errs []error
+		)
+
+		wg.Add(n)
+
+		for i := 0; i < n; i++ {
+			f := g(i)
+
+			inCh := GoCtx(withIndex(ctx, i), func(ctx context.Context, ch chan<- Option[T]) error {
+				start := clockFrom(ctx).Now()
+
+				if err := call(ctx, f, ch); err != nil {
+					return TrySendError[T](ctx, ch, newTaskError(ctx, start, err, IsPanic(err)))
+				}
+
+				return nil
+			}, 1)
+
+			go func() {
+				defer wg.Done()
+				defer prog.finish()
+
+				for v := range inCh {
+					if err := v.Err(); err != nil {
+						mu.Lock()
+						errs = append(errs, err)
+						mu.Unlock()
+
+						continue
+					}
+
+					outCh <- v
+				}
+
+				if opt, ok := takeFailure(scope, inCh); ok {
+					mu.Lock()
+					errs = append(errs, opt.Err())
+					mu.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			return TrySendError[T](ctx, outCh, err)
+		}
+
+		return nil
+	}
+
+	return Go(withoutLimits(ctx), fn, capacity...)
+}
+
+// GroupWithTimeout runs g(i) functions in parallel like Group, but each function is called with its own context
+// expiring after perTask. The error of the timed out function falls into the channel instead of failing the group,
+// so the other functions keep running.
+func GroupWithTimeout[T any](ctx context.Context, g func(i int) FuncCtx[T], n int, perTask time.Duration, capacity ...int) <-chan Option[T] {
+	withTimeout := func(i int) Func[T] {
+		f := g(i)
+
+		return func(ch chan<- Option[T]) error {
+			taskCtx, cancel := withTimeout(ctx, perTask)
+			defer cancel()
+
+			err := f(taskCtx, ch)
+			if err != nil && ctx.Err() == nil && errors.Is(context.Cause(taskCtx), context.DeadlineExceeded) {
+				return TrySendError[T](ctx, ch, err)
+			}
+
+			return err
+		}
+	}
+
+	return Group(ctx, withTimeout, n, capacity...)
+}
+
+// GroupSlice runs f over every element of in in parallel like Group, results fall into one channel.
+// The context of the group is modified by opt funcs like GoWith, e.g. WithCapacity or WithSemaphore.
+func GroupSlice[In, Out any](ctx context.Context, in []In, f func(In) (Out, error), opt ...OptFunc) <-chan Option[Out] {
+	ctx, capacity := applyTask(ctx, opt)
+
+	g := func(i int) Func[Out] {
+		return func(ch chan<- Option[Out]) error {
+			value, err := f(in[i])
+			if err != nil {
+				return err
+			}
+
+			ch <- MakeValue(value)
+
+			return nil
+		}
+	}
+
+	return Group(ctx, g, len(in), capacity...)
+}
+
+// GroupMap runs f over every entry of in in parallel like GroupSlice, results fall into one channel with their keys.
+func GroupMap[K comparable, In, Out any](ctx context.Context, in map[K]In, f func(K, In) (Out, error), opt ...OptFunc) <-chan Option[KV[K, Out]] {
+	entries := make([]KV[K, In], 0, len(in))
+	for k, v := range in {
+		entries = append(entries, KV[K, In]{Key: k, Value: v})
+	}
+
+	fn := func(e KV[K, In]) (KV[K, Out], error) {
+		value, err := f(e.Key, e.Value)
+		if err != nil {
+			return KV[K, Out]{}, err
+		}
+
+		return KV[K, Out]{Key: e.Key, Value: value}, nil
+	}
+
+	return GroupSlice(ctx, entries, fn, opt...)
+}
+
+// GroupChan consumes the in channel with workers functions f running in parallel like Group, results fall into one channel.
+// Errors returned by f are passed to the channel element by element and don't stop the workers.
+// The channel is closed after the in channel is closed. Interrupted by closed context.
+func GroupChan[In, Out any](ctx context.Context, in <-chan In, workers int, f func(In) (Out, error), capacity ...int) <-chan Option[Out] {
+	worker := func(ch chan<- Option[Out]) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case v, ok := <-in:
+				if !ok {
+					return nil
+				}
+
+				value, err := f(v)
+
+				select {
+				case ch <- Option[Out]{value: value, err: err}:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	}
+
+	return Group(ctx, func(int) Func[Out] { return worker }, max(workers, 1), capacity...)
+}
@@ -0,0 +1,336 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestGroupN(t *testing.T) {
+	const testN = 50
+	const testLimit = 3
+
+	ctx := context.Background()
+
+	var running, maxRunning atomic.Int32
+
+	ch := async.GroupN(
+		ctx,
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+
+				ch <- async.MakeValue(i)
+
+				return nil
+			}
+		},
+		testN,
+		testLimit,
+	)
+
+	var count int
+
+	for opt := range ch {
+		if err := opt.Err(); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		count++
+	}
+
+	if count != testN {
+		t.Error(count)
+	}
+
+	if maxRunning.Load() > testLimit {
+		t.Error(maxRunning.Load())
+	}
+}
+
+func TestGroupN_Canceled(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background(), async.CollectErrors())
+
+	items := []int{0, 1, 2, 3}
+
+	// ForEach cancels the group after the first error, the cancellation doesn't fail the scope.
+	err := async.ForEach(scope.Context(), items, 1, func(ctx context.Context, item int) error {
+		if item == 0 {
+			return testErr
+		}
+
+		return nil
+	})
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+
+		return
+	}
+
+	if err := scope.Wait(); !errors.Is(err, testErr) || errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+}
+
+func TestGroupOrdered(t *testing.T) {
+	const testN = 10
+
+	ctx := context.Background()
+
+	ch := async.GroupOrdered(
+		ctx,
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				// Finish in reverse order.
+				<-time.After(time.Duration(testN-i) * time.Millisecond)
+				ch <- async.MakeValue(i)
+
+				return nil
+			}
+		},
+		testN,
+	)
+
+	var want int
+
+	for opt := range ch {
+		if err := opt.Err(); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		if opt.Value() != want {
+			t.Error(opt.Value(), want)
+
+			return
+		}
+
+		want++
+	}
+
+	if want != testN {
+		t.Error(want)
+	}
+}
+
+func TestGroupAll(t *testing.T) {
+	const testN = 10
+
+	errOdd := errors.New("odd")
+
+	ctx := context.Background()
+
+	ch := async.GroupAll(
+		ctx,
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				if i%2 != 0 {
+					return fmt.Errorf("%d: %w", i, errOdd)
+				}
+
+				ch <- async.MakeValue(i)
+
+				return nil
+			}
+		},
+		testN,
+	)
+
+	var (
+		values int
+		errs   []error
+	)
+
+	for opt := range ch {
+		if err := opt.Err(); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		values++
+	}
+
+	if values != testN/2 {
+		t.Error(values)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], errOdd) {
+		t.Error(errs)
+
+		return
+	}
+
+	joined, ok := errs[0].(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != testN/2 {
+		t.Error(errs[0])
+	}
+}
+
+func TestGroupAll_Panic(t *testing.T) {
+	scope := async.NewScope(context.Background())
+
+	ch := async.GroupAll(
+		scope.Context(),
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				if i == 1 {
+					panic("something went wrong!")
+				}
+
+				ch <- async.MakeValue(i)
+
+				return nil
+			}
+		},
+		2,
+	)
+
+	values, err := async.CollectAll(scope.Context(), ch)
+	if len(values) != 1 || values[0] != 0 {
+		t.Error(values)
+
+		return
+	}
+
+	// The panic is collected like the returned error and doesn't cancel the scope.
+	var taskErr *async.TaskError
+	if !errors.As(err, &taskErr) || taskErr.Index != 1 || !taskErr.Panicked || !async.IsPanic(err) {
+		t.Error(err)
+
+		return
+	}
+
+	if err := scope.Wait(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGroupWithTimeout(t *testing.T) {
+	const testN = 3
+
+	ctx, cancel := async.With(context.Background())
+	defer cancel()
+
+	ch := async.GroupWithTimeout(
+		ctx,
+		func(i int) async.FuncCtx[int] {
+			return func(ctx context.Context, ch chan<- async.Option[int]) error {
+				if i == 0 {
+					<-ctx.Done()
+
+					return ctx.Err()
+				}
+
+				return async.TrySend(ctx, ch, i)
+			}
+		},
+		testN,
+		50*time.Millisecond,
+	)
+
+	values, err := async.CollectAll(ctx, ch)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error(err)
+	}
+
+	if len(values) != testN-1 {
+		t.Error(values)
+	}
+
+	if err := async.Err(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGroupSlice(t *testing.T) {
+	ctx := context.Background()
+
+	in := []string{"a", "bb", "ccc"}
+
+	ch := async.GroupSlice(ctx, in, func(s string) (int, error) { return len(s), nil }, async.WithCapacity(len(in)))
+
+	sum, err := async.Reduce(ctx, ch, 0, func(acc, v int) (int, error) { return acc + v, nil })
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if sum != 6 {
+		t.Error(sum)
+	}
+}
+
+func TestGroupMap(t *testing.T) {
+	ctx := context.Background()
+
+	in := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	ch := async.GroupMap(ctx, in, func(k string, v int) (string, error) { return strings.Repeat(k, v), nil })
+
+	out, err := async.CollectMap(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(out) != 3 || out["a"] != "a" || out["c"] != "ccc" {
+		t.Error(out)
+	}
+}
+
+func TestGroupChan(t *testing.T) {
+	const testN = 20
+
+	errOdd := errors.New("odd")
+
+	ctx := context.Background()
+
+	in := make(chan int)
+
+	go func() {
+		defer close(in)
+
+		for i := 0; i < testN; i++ {
+			in <- i
+		}
+	}()
+
+	ch := async.GroupChan(ctx, in, 4, func(v int) (int, error) {
+		if v%2 != 0 {
+			return 0, errOdd
+		}
+
+		return v, nil
+	})
+
+	values, err := async.CollectAll(ctx, ch)
+	if !errors.Is(err, errOdd) {
+		t.Error(err)
+	}
+
+	if len(values) != testN/2 {
+		t.Error(values)
+	}
+}
@@ -0,0 +1,133 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+var contextKeyGuard contextKey = "guard"
+
+var (
+	// guardOwners maps channels of running guarded tasks to their scopes.
+	guardOwners sync.Map
+	// guardTasks is the number of running guarded tasks, recv doesn't look up owners if there are none of them.
+	guardTasks atomic.Int64
+)
+
+// WithGuard makes tasks started with the context detect misuse of their channels and log it with the logger
+// set by WithLogger: the task function closes its channel, or the channel is awaited with the context
+// of an unrelated scope. Building with the asyncguard tag enables the guard for all tasks.
+// The task function writes to its channel directly, so the delivery is the same as without the guard.
+// A send after the function has returned panics at the sender like the send to any closed channel.
+func WithGuard() OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyGuard, true)
+	}
+	return fn
+}
+
+func guarded(ctx context.Context) bool {
+	if guardBuild {
+		return true
+	}
+
+	on, _ := ctx.Value(contextKeyGuard).(bool)
+
+	return on
+}
+
+// guard registers the ch channel of the guarded task at its scope, the returned func unregisters it.
+func guard[T any](ctx context.Context, ch chan Option[T]) func() {
+	key := (<-chan Option[T])(ch)
+
+	guardOwners.Store(key, scopeFrom(ctx))
+	guardTasks.Add(1)
+
+	return func() {
+		guardOwners.Delete(key)
+		guardTasks.Add(-1)
+	}
+}
+
+// closeGuarded closes the ch channel of the guarded task and reports the channel already closed by the task function.
+func closeGuarded[T any](ctx context.Context, ch chan Option[T]) {
+	defer func() {
+		if recover() != nil {
+			reportMisuse(ctx, "task function has closed its channel")
+		}
+	}()
+
+	close(ch)
+}
+
+// sendFailGuarded passes err of the failed guarded task like sendFailError.
+// If the task function has closed the channel, err is lost, closeGuarded reports the misuse.
+func sendFailGuarded[T any](ctx context.Context, ch chan Option[T], err error) (_ error) {
+	scope := scopeFrom(ctx)
+
+	lost := func() (lost bool) {
+		defer func() {
+			if recover() != nil {
+				lost = true
+			}
+		}()
+
+		return passFailure(ctx, scope, ch, err)
+	}()
+
+	if scope != nil {
+		scope.fail((<-chan Option[T])(ch), err)
+
+		return nil
+	}
+
+	if lost {
+		return err
+	}
+
+	return nil
+}
+
+// checkOwner reports awaiting the ch channel of the guarded task with the context of an unrelated scope.
+func checkOwner[T any](ctx context.Context, ch <-chan Option[T]) {
+	if guardTasks.Load() == 0 {
+		return
+	}
+
+	v, ok := guardOwners.Load(ch)
+	if !ok {
+		return
+	}
+
+	owner, scope := v.(*Scope), scopeFrom(ctx)
+	if owner == nil || scope == nil || owner.related(scope) {
+		return
+	}
+
+	// The misuse is reported once per channel.
+	if guardOwners.CompareAndSwap(ch, owner, (*Scope)(nil)) {
+		reportMisuse(ctx, "task channel is awaited from a different scope")
+	}
+}
+
+// related reports whether s and other are the same scope or one of them is nested in the other, see Scope.Child.
+func (s *Scope) related(other *Scope) bool {
+	for p := s; p != nil; p = p.parent {
+		if p == other {
+			return true
+		}
+	}
+
+	for p := other; p != nil; p = p.parent {
+		if p == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func reportMisuse(ctx context.Context, msg string) {
+	loggerFrom(ctx).ErrorContext(ctx, "async: misuse: "+msg, "task", nameFrom(ctx))
+}
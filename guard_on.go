@@ -0,0 +1,6 @@
+//go:build asyncguard
+
+package async
+
+// guardBuild enables the guard for all tasks, see WithGuard.
+const guardBuild = true
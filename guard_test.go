@@ -0,0 +1,150 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+	"github.com/WinPooh32/async/v2/asynctest"
+)
+
+func (l *testLogger) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.msgs)
+}
+
+func TestWithGuardDelivery(t *testing.T) {
+	ctx := async.WithGuard()(context.Background())
+
+	sent := make(chan struct{})
+
+	ch := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+
+		close(sent)
+
+		return nil
+	})
+
+	// The send is blocked until the consumer receives the value, the same way as without the guard.
+	select {
+	case <-sent:
+		t.Error("value is sent ahead of the consumer")
+
+		return
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if v, err := async.Await(ctx, ch); err != nil || v != 1 {
+		t.Error(v, err)
+	}
+}
+
+func TestWithGuardClose(t *testing.T) {
+	logger := new(testLogger)
+
+	ctx := async.WithGuard()(async.WithLogger(logger)(context.Background()))
+
+	ch := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		close(ch)
+
+		return nil
+	})
+
+	if _, err := async.Collect(context.Background(), ch); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	asynctest.Eventually(t, func() bool { return logger.len() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestWithGuardForeignScope(t *testing.T) {
+	logger := new(testLogger)
+
+	ctx := async.WithGuard()(async.WithLogger(logger)(context.Background()))
+
+	owner := async.NewScope(ctx)
+	defer owner.Cancel()
+
+	child := owner.Child()
+	defer child.Cancel()
+
+	foreign := async.NewScope(ctx)
+	defer foreign.Cancel()
+
+	ch := async.Go(owner.Context(), func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+		ch <- async.MakeValue(2)
+
+		return nil
+	})
+
+	if _, err := async.Await(child.Context(), ch); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if logger.len() != 0 {
+		t.Error(logger.msgs)
+
+		return
+	}
+
+	if _, err := async.Collect(foreign.Context(), ch); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if logger.len() != 1 {
+		t.Error(logger.msgs)
+	}
+}
+
+func TestWithGuardCancelledConsumer(t *testing.T) {
+	const testN = 10
+
+	logger := new(testLogger)
+
+	scope := async.NewScope(async.WithGuard()(async.WithLogger(logger)(context.Background())))
+
+	ch := async.Go(scope.Context(), func(ch chan<- async.Option[int]) error {
+		for i := range testN {
+			ch <- async.MakeValue(i)
+		}
+
+		return nil
+	})
+
+	<-ch
+
+	scope.Cancel()
+
+	n := 1
+
+	for range ch {
+		n++
+	}
+
+	if n != testN {
+		t.Error(n)
+
+		return
+	}
+
+	if err := scope.WaitTimeout(time.Second); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if logger.len() != 0 {
+		t.Error(logger.msgs)
+	}
+}
@@ -0,0 +1,94 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hedge calls function f and calls it again at a new goroutine every delay while none of the calls succeeded,
+// up to maxHedges extra calls. The next call is also made at once when all running calls have failed.
+// The first value received from any of the calls falls into the returned channel, then the context passed to f
+// is cancelled to stop the other calls, their outputs are discarded. If all calls fail, their errors are joined
+// by errors.Join. Errors of single calls don't fail the scope of ctx, only the error of the whole Hedge does.
+func Hedge[T any](ctx context.Context, f FuncCtx[T], delay time.Duration, maxHedges int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		callCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		results := make(chan Option[T], max(maxHedges, 0)+1)
+
+		start := func() {
+			in := make(chan Option[T], 1)
+
+			go func() {
+				defer close(in)
+
+				if err := call(callCtx, func(ch chan<- Option[T]) error { return f(callCtx, ch) }, in); err != nil {
+					in <- MakeErr[T](err)
+				}
+			}()
+
+			go func() {
+				opt, ok := <-in
+				if !ok {
+					opt = MakeErr[T](ErrChannelClosed)
+				}
+
+				results <- opt
+
+				for range in {
+				}
+			}()
+		}
+
+		timer := clockFrom(ctx).NewTimer(delay)
+		defer timer.Stop()
+
+		start()
+
+		var (
+			started = 1
+			errs    []error
+		)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+
+			case <-timer.C():
+				if started <= maxHedges {
+					start()
+					started++
+
+					timer.Reset(delay)
+				}
+
+			case opt := <-results:
+				if err := opt.Err(); err == nil {
+					cancel()
+
+					return TrySend(ctx, ch, opt.Value())
+				}
+
+				errs = append(errs, opt.Err())
+
+				if len(errs) < started {
+					continue
+				}
+
+				if started > maxHedges {
+					return errors.Join(errs...)
+				}
+
+				start()
+				started++
+
+				timer.Reset(delay)
+			}
+		}
+	}
+
+	return Go(ctx, fn, 1)
+}
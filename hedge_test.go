@@ -0,0 +1,82 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestHedge(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		calls   atomic.Int32
+		stopped = make(chan struct{})
+	)
+
+	ch := async.Hedge(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+		n := calls.Add(1)
+		if n == 1 {
+			select {
+			case <-ctx.Done():
+				close(stopped)
+
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+
+		ch <- async.MakeValue(int(n))
+
+		return nil
+	}, 10*time.Millisecond, 2)
+
+	v, err := async.Await(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	// The slow first call is overtaken by the hedged one.
+	if v != 2 {
+		t.Error(v)
+
+		return
+	}
+
+	// The losing call is cancelled.
+	select {
+	case <-stopped:
+	case <-time.After(500 * time.Millisecond):
+		t.Error("losing call is not cancelled")
+	}
+}
+
+func TestHedge_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx, cancel := async.With(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+
+	ch := async.Hedge(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+		calls.Add(1)
+
+		return testErr
+	}, time.Hour, 2)
+
+	if _, err := async.Await(ctx, ch); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	// Failed calls are repeated at once.
+	if n := calls.Load(); n != 3 {
+		t.Error(n)
+	}
+}
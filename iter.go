@@ -0,0 +1,49 @@
+package async
+
+import (
+	"context"
+	"iter"
+)
+
+// Iter returns an iterator over values and errors of the ch channel, so it can be ranged with a for loop.
+// When ctx is done or the context's scope fails, the last pair contains the error.
+// If the loop is stopped early, the rest of the channel is drained in background.
+func Iter[T any](ctx context.Context, ch <-chan Option[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			opt, ok, err := recv(ctx, ch)
+			if err != nil {
+				Abandon(ch)
+				yield(opt.Value(), err)
+
+				return
+			}
+
+			if !ok {
+				return
+			}
+
+			if !yield(opt.Value(), opt.Err()) {
+				go drain(ctx, ch)
+
+				return
+			}
+		}
+	}
+}
+
+// FromSeq passes values of the seq iterator to the returned channel at a new goroutine.
+// Interrupted by closed context.
+func FromSeq[T any](ctx context.Context, seq iter.Seq[T], capacity ...int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		for v := range seq {
+			if err := TrySend(ctx, ch, v); err != nil {
+				return nil
+			}
+		}
+
+		return nil
+	}
+
+	return Go(ctx, fn, capacity...)
+}
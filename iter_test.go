@@ -0,0 +1,49 @@
+package async_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestIter(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.FromSeq(ctx, slices.Values([]int{1, 2, 3}))
+
+	var got []int
+
+	for v, err := range async.Iter(ctx, ch) {
+		if err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		got = append(got, v)
+	}
+
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Error(got)
+	}
+}
+
+func TestIter_Break(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.FromSeq(ctx, slices.Values([]int{1, 2, 3}))
+
+	for v, err := range async.Iter(ctx, ch) {
+		if err != nil {
+			t.Error(err)
+		}
+
+		if v != 1 {
+			t.Error(v)
+		}
+
+		break
+	}
+}
@@ -0,0 +1,139 @@
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// jsonCodeErr is the sentinel error registered with the code, see RegisterJSONError.
+type jsonCodeErr struct {
+	code string
+	err  error
+}
+
+var jsonErrors = struct {
+	sync.RWMutex
+	// codes are checked in the order they were registered, so the error matching several of them gets the first one.
+	codes []jsonCodeErr
+}{
+	codes: []jsonCodeErr{
+		{"channel_closed", ErrChannelClosed},
+		{"channel_full", ErrChannelFull},
+		{"await_timeout", ErrAwaitTimeout},
+		{"wait_timeout", ErrWaitTimeout},
+		{"task_timeout", ErrTaskTimeout},
+		{"task_stuck", ErrTaskStuck},
+		{"circuit_open", ErrCircuitOpen},
+		{"pool_closed", ErrPoolClosed},
+		{"broadcast_closed", ErrBroadcastClosed},
+		{"canceled", context.Canceled},
+		{"deadline_exceeded", context.DeadlineExceeded},
+	},
+}
+
+// RegisterJSONError registers the sentinel error err with the code. Errors matching err by errors.Is
+// are encoded with the code, so they still match err after they are decoded by Option.UnmarshalJSON.
+// Errors of the package and context errors are registered by default. The error matching several sentinels
+// is encoded with the code registered first, registering the code again replaces its error in place.
+func RegisterJSONError(code string, err error) {
+	jsonErrors.Lock()
+	defer jsonErrors.Unlock()
+
+	for i := range jsonErrors.codes {
+		if jsonErrors.codes[i].code == code {
+			jsonErrors.codes[i].err = err
+
+			return
+		}
+	}
+
+	jsonErrors.codes = append(jsonErrors.codes, jsonCodeErr{code: code, err: err})
+}
+
+// JSONError is an error decoded by Option.UnmarshalJSON.
+type JSONError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *JSONError) Error() string { return e.Message }
+
+// Unwrap returns the sentinel error registered with e's code, so errors.Is and errors.As work with it.
+func (e *JSONError) Unwrap() error {
+	if e.Code == "" {
+		return nil
+	}
+
+	jsonErrors.RLock()
+	defer jsonErrors.RUnlock()
+
+	for _, c := range jsonErrors.codes {
+		if c.code == e.Code {
+			return c.err
+		}
+	}
+
+	return nil
+}
+
+type jsonOption[T any] struct {
+	Value *T         `json:"value,omitempty"`
+	Error *JSONError `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes opt as {"value": value} or {"error": {"code": code, "message": message}},
+// partial option has both of them.
+func (opt Option[T]) MarshalJSON() ([]byte, error) {
+	if opt.err == nil {
+		return json.Marshal(jsonOption[T]{Value: &opt.value})
+	}
+
+	v := jsonOption[T]{Error: &JSONError{Code: jsonCode(opt.err), Message: opt.err.Error()}}
+	if opt.partial {
+		v.Value = &opt.value
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes opt encoded by MarshalJSON. Decoded error is *JSONError.
+func (opt *Option[T]) UnmarshalJSON(data []byte) error {
+	var v jsonOption[T]
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*opt = Option[T]{}
+
+	if v.Value != nil {
+		opt.value = *v.Value
+	}
+
+	if v.Error != nil {
+		opt.err = v.Error
+		opt.partial = v.Value != nil
+	}
+
+	return nil
+}
+
+func jsonCode(err error) string {
+	var je *JSONError
+	if errors.As(err, &je) && je.Code != "" {
+		return je.Code
+	}
+
+	jsonErrors.RLock()
+	defer jsonErrors.RUnlock()
+
+	for _, c := range jsonErrors.codes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,132 @@
+package async_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestOption_JSONValue(t *testing.T) {
+	data, err := json.Marshal(async.MakeValue("hello"))
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if string(data) != `{"value":"hello"}` {
+		t.Error(string(data))
+	}
+
+	var opt async.Option[string]
+
+	if err := json.Unmarshal(data, &opt); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if opt.Value() != "hello" || opt.Err() != nil {
+		t.Error(opt)
+	}
+}
+
+func TestOption_JSONError(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	async.RegisterJSONError("not_found", errNotFound)
+
+	for _, testErr := range []error{
+		fmt.Errorf("user 1: %w", errNotFound),
+		fmt.Errorf("request: %w", context.DeadlineExceeded),
+	} {
+		data, err := json.Marshal(async.MakeErr[int](testErr))
+		if err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		var opt async.Option[int]
+
+		if err := json.Unmarshal(data, &opt); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		if opt.Err() == nil || opt.Err().Error() != testErr.Error() {
+			t.Error(opt.Err())
+		}
+
+		if !errors.Is(opt.Err(), errors.Unwrap(testErr)) {
+			t.Error(string(data))
+		}
+
+		var jsonErr *async.JSONError
+		if !errors.As(opt.Err(), &jsonErr) {
+			t.Error(opt.Err())
+		}
+	}
+}
+
+func TestOption_JSONErrorCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code string
+	}{
+		{async.ErrTaskTimeout, "task_timeout"},
+		{async.ErrCircuitOpen, "circuit_open"},
+		{async.ErrTaskStuck, "task_stuck"},
+		{async.ErrChannelFull, "channel_full"},
+		{async.ErrBroadcastClosed, "broadcast_closed"},
+		// The joined error matches both sentinels, the one registered first wins.
+		{errors.Join(context.Canceled, async.ErrTaskTimeout), "task_timeout"},
+	} {
+		data, err := json.Marshal(async.MakeErr[int](tc.err))
+		if err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		var v struct {
+			Error async.JSONError `json:"error"`
+		}
+
+		if err := json.Unmarshal(data, &v); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		if v.Error.Code != tc.code {
+			t.Error(string(data))
+		}
+	}
+}
+
+func TestOption_JSONPartial(t *testing.T) {
+	data, err := json.Marshal(async.MakeValueErr(1, errors.New("truncated")))
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	var opt async.Option[int]
+
+	if err := json.Unmarshal(data, &opt); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if !opt.IsPartial() || opt.Value() != 1 || opt.Err() == nil {
+		t.Error(string(data))
+	}
+}
@@ -0,0 +1,64 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errAbandoned is the cancellation cause of the producer whose channel is abandoned.
+var errAbandoned = errors.New("channel is abandoned")
+
+// linked maps channels returned by GoLinked to cancel funcs of their producers.
+var linked sync.Map
+
+// GoLinked runs function f at a new goroutine like GoCtx, but f receives the context derived from ctx,
+// which is cancelled when the consumer abandons the returned channel: Await, Collect, Reduce or Iter
+// are interrupted by closed context or stopped early, or Abandon is called.
+// So f can observe ctx.Done() and exit instead of being blocked on send forever.
+func GoLinked[T any](ctx context.Context, f FuncCtx[T], capacity ...int) <-chan Option[T] {
+	ch := makeChan[T](capacity...)
+
+	goLinked(ctx, f, ch)
+
+	return ch
+}
+
+func goLinked[T any](ctx context.Context, f FuncCtx[T], ch chan Option[T]) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	key := (<-chan Option[T])(ch)
+
+	linked.Store(key, cancel)
+
+	ctx, done := track(ctx)
+
+	spawn(
+		ctx,
+		func() {
+			defer done()
+			defer cancel(nil)
+			defer linked.Delete(key)
+
+			run(ctx, f, ch)
+		},
+		func(err error) {
+			defer done()
+			defer cancel(nil)
+			defer linked.Delete(key)
+
+			run(ctx, fail[T](err), ch)
+		},
+	)
+}
+
+// Abandon tells the producer of the ch channel started by GoLinked that nobody reads the channel anymore.
+// The failure of the task kept by its scope, when the channel had no room for it, is dropped too.
+// It does nothing for other channels.
+func Abandon[T any](ch <-chan Option[T]) {
+	if cancel, ok := linked.Load(ch); ok {
+		cancel.(context.CancelCauseFunc)(errAbandoned)
+	}
+
+	forgetFailure(ch)
+}
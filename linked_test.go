@@ -0,0 +1,65 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestGoLinked_Abandoned(t *testing.T) {
+	exited := make(chan error, 1)
+
+	ch := async.GoLinked(
+		context.Background(),
+		func(ctx context.Context, ch chan<- async.Option[int]) error {
+			<-ctx.Done()
+			exited <- ctx.Err()
+
+			return nil
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := async.Await(ctx, ch)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error(err)
+
+		return
+	}
+
+	select {
+	case err := <-exited:
+		if !errors.Is(err, context.Canceled) {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Error("producer is not cancelled")
+	}
+}
+
+func TestGoLinked_Value(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.GoLinked(
+		ctx,
+		func(ctx context.Context, ch chan<- async.Option[int]) error {
+			return async.TrySend(ctx, ch, 1)
+		},
+	)
+
+	v, err := async.Await(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,30 @@
+package async
+
+import (
+	"context"
+	"log/slog"
+)
+
+var contextKeyLogger contextKey = "logger"
+
+// Logger logs internal errors of the package. *slog.Logger implements it.
+// Args are alternating keys and values like in slog.
+type Logger interface {
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+// WithLogger sets the logger for tasks started with the context. By default slog.Default() is used.
+func WithLogger(l Logger) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyLogger, l)
+	}
+	return fn
+}
+
+func loggerFrom(ctx context.Context) Logger {
+	if l, _ := ctx.Value(contextKeyLogger).(Logger); l != nil {
+		return l
+	}
+
+	return slog.Default()
+}
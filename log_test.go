@@ -0,0 +1,56 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+type testLogger struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *testLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.msgs = append(l.msgs, msg)
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := new(testLogger)
+
+	ctx, cancel := context.WithCancel(async.WithLogger(logger)(context.Background()))
+
+	// The channel's buffer is full until the context is done, so the error can't be sent and is logged.
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+
+			return errors.New("test error")
+		},
+		1,
+	)
+
+	<-time.After(50 * time.Millisecond)
+
+	cancel()
+
+	<-time.After(50 * time.Millisecond)
+
+	for range ch {
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if len(logger.msgs) != 1 {
+		t.Error(logger.msgs)
+	}
+}
@@ -0,0 +1,148 @@
+package async
+
+import (
+	"context"
+)
+
+var (
+	contextKeyMaxTasks contextKey = "max_tasks"
+	contextKeyUnqueued contextKey = "unqueued"
+)
+
+// queuedTask is the task waiting for a free slot of the scope, see WithMaxTasks.
+type queuedTask struct {
+	start func()
+	stop  func() bool
+}
+
+// WithMaxTasks limits the number of concurrently running tasks of the new scope, see NewScope and With.
+// Unlike WithSemaphore, goroutines of the tasks over the limit are not started: the tasks are queued
+// and started in order when running tasks return. If the context of the queued task is done,
+// the task is removed from the queue and fails with ctx error without calling its function.
+// Drivers of Group functions don't take slots, only the grouped functions do.
+// A task waiting on another task of the same scope holds its slot, so n must leave room for the awaited tasks.
+func WithMaxTasks(n int) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyMaxTasks, n)
+	}
+	return fn
+}
+
+// spawn calls start at a new goroutine, or queues it until the scope of ctx and all its parent scopes
+// with limits have free slots, see Scope.Child. The slots are taken from the scope up to the root.
+// The abort func is called instead of start, when ctx is done before the task is started.
+func spawn(ctx context.Context, start func(), abort func(err error)) {
+	var limits []*Scope
+
+	if ctx.Value(contextKeyUnqueued) == nil {
+		for s := scopeFrom(ctx); s != nil; s = s.parent {
+			if s.maxTasks > 0 {
+				limits = append(limits, s)
+			}
+		}
+	}
+
+	take(ctx, limits, 0, start, abort)
+}
+
+// take takes slots of limits starting from the i-th one and calls start at a new goroutine.
+// If the scope has no free slot, the task is queued there and take continues when the slot is passed to it.
+func take(ctx context.Context, limits []*Scope, i int, start func(), abort func(err error)) {
+	for ; i < len(limits); i++ {
+		next := func() { take(ctx, limits, i+1, start, abort) }
+
+		fail := func(err error) {
+			releaseAll(limits[:i])
+			abort(err)
+		}
+
+		if !limits[i].acquire(ctx, next, fail) {
+			return
+		}
+	}
+
+	go func() {
+		defer releaseAll(limits)
+
+		start()
+	}()
+}
+
+// acquire takes the free slot of the scope, otherwise it queues next to be called when the slot is passed to it
+// and reports false. The abort func is called instead of next, when ctx is done before.
+func (s *Scope) acquire(ctx context.Context, next func(), abort func(err error)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running < s.maxTasks {
+		s.running++
+
+		return true
+	}
+
+	task := &queuedTask{start: next}
+
+	task.stop = context.AfterFunc(ctx, func() {
+		if s.dequeue(task) {
+			abort(ctx.Err())
+		}
+	})
+
+	s.queue = append(s.queue, task)
+
+	return false
+}
+
+func releaseAll(limits []*Scope) {
+	for _, s := range limits {
+		s.release()
+	}
+}
+
+// release passes the slot to the next queued task.
+func (s *Scope) release() {
+	s.mu.Lock()
+
+	if len(s.queue) == 0 {
+		s.running--
+		s.mu.Unlock()
+
+		return
+	}
+
+	task := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+
+	s.mu.Unlock()
+
+	task.stop()
+	task.start()
+}
+
+// dequeue removes the task from the queue, it reports whether the task was queued.
+func (s *Scope) dequeue(task *queuedTask) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, t := range s.queue {
+		if t == task {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// withoutQueue makes the task of ctx bypass the queue of the scope, see WithMaxTasks.
+func withoutQueue(ctx context.Context) context.Context {
+	for s := scopeFrom(ctx); s != nil; s = s.parent {
+		if s.maxTasks > 0 {
+			return context.WithValue(ctx, contextKeyUnqueued, true)
+		}
+	}
+
+	return ctx
+}
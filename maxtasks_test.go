@@ -0,0 +1,186 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestWithMaxTasks(t *testing.T) {
+	const (
+		testN   = 20
+		testMax = 3
+	)
+
+	scope := async.NewScope(context.Background(), async.WithMaxTasks(testMax))
+
+	var running, peak atomic.Int32
+
+	for i := 0; i < testN; i++ {
+		scope.Go(func(ctx context.Context) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+
+			<-time.After(5 * time.Millisecond)
+
+			return nil
+		})
+	}
+
+	if err := scope.Wait(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if p := peak.Load(); p != testMax {
+		t.Error(p)
+	}
+}
+
+func TestWithMaxTasks_Queued(t *testing.T) {
+	const testN = 100
+
+	ctx, cancel := async.With(context.Background(), async.WithMaxTasks(1))
+	defer cancel()
+
+	block := make(chan struct{})
+
+	async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		<-block
+
+		return nil
+	})
+
+	before := runtime.NumGoroutine()
+
+	chans := make([]<-chan async.Option[int], testN)
+
+	for i := range chans {
+		chans[i] = async.Go(ctx, func(ch chan<- async.Option[int]) error { return nil }, 1)
+	}
+
+	// Queued tasks don't start goroutines.
+	if n := runtime.NumGoroutine(); n >= before+testN {
+		t.Error(n - before)
+	}
+
+	cancel()
+
+	// Queued tasks fail without waiting for the slot.
+	for _, ch := range chans {
+		if _, err := async.Await(context.Background(), ch); !errors.Is(err, context.Canceled) {
+			t.Error(err)
+
+			break
+		}
+	}
+
+	close(block)
+}
+
+func TestWithMaxTasks_Group(t *testing.T) {
+	const testN = 10
+
+	ctx, cancel := async.With(context.Background(), async.WithMaxTasks(1))
+	defer cancel()
+
+	ch := async.Group(ctx, func(i int) async.Func[int] {
+		return func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(i)
+
+			return nil
+		}
+	}, testN)
+
+	values, err := async.Collect(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(values) != testN {
+		t.Error(values)
+	}
+}
+
+func TestWithMaxTasks_Child(t *testing.T) {
+	const (
+		testN   = 3
+		testMax = 2
+	)
+
+	scope := async.NewScope(context.Background(), async.WithMaxTasks(testMax))
+	defer scope.Cancel()
+
+	var running, peak atomic.Int32
+
+	task := func(ctx context.Context) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		<-time.After(5 * time.Millisecond)
+
+		return nil
+	}
+
+	// Children share the limit of the parent.
+	scopes := []*async.Scope{scope, scope.Child(), scope.Child(), scope.Child()}
+
+	for _, s := range scopes {
+		for i := 0; i < testN; i++ {
+			s.Go(task)
+		}
+	}
+
+	if err := scope.Wait(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if p := peak.Load(); p != testMax {
+		t.Error(p)
+
+		return
+	}
+
+	// The own limit of the child is applied along with the parent's one.
+	peak.Store(0)
+
+	child := scope.Child(async.WithMaxTasks(1))
+
+	for i := 0; i < testN; i++ {
+		child.Go(task)
+	}
+
+	if err := child.Wait(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if p := peak.Load(); p != 1 {
+		t.Error(p)
+	}
+}
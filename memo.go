@@ -0,0 +1,67 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memo caches results of tasks by key for the TTL, see Memoize.
+type Memo[K comparable, T any] struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[K]memoEntry[T]
+	flights SingleFlight[K, T]
+}
+
+type memoEntry[T any] struct {
+	value   T
+	expires time.Time
+}
+
+// Memoize returns the new cache keeping results for ttl.
+func Memoize[K comparable, T any](ttl time.Duration) *Memo[K, T] {
+	return &Memo[K, T]{
+		ttl:     ttl,
+		entries: make(map[K]memoEntry[T]),
+	}
+}
+
+// Get returns the cached value of the key if it's not expired yet.
+// Otherwise it runs function f like SingleFlight.Do, so concurrent calls with the same key share one task,
+// and caches its value. Errors are not cached. Can be interrupted by closed context.
+func (m *Memo[K, T]) Get(ctx context.Context, key K, f Func[T]) (T, error) {
+	m.mu.Lock()
+
+	if e, ok := m.entries[key]; ok {
+		if clockFrom(ctx).Now().Before(e.expires) {
+			m.mu.Unlock()
+
+			return e.value, nil
+		}
+
+		delete(m.entries, key)
+	}
+
+	m.mu.Unlock()
+
+	value, err := m.flights.Do(ctx, key, f)
+	if err != nil {
+		return value, err
+	}
+
+	m.mu.Lock()
+	m.entries[key] = memoEntry[T]{value: value, expires: clockFrom(ctx).Now().Add(m.ttl)}
+	m.mu.Unlock()
+
+	return value, nil
+}
+
+// Forget removes the cached value of the key.
+func (m *Memo[K, T]) Forget(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+}
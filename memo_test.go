@@ -0,0 +1,70 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestMemoize(t *testing.T) {
+	ctx := context.Background()
+
+	memo := async.Memoize[string, int](50 * time.Millisecond)
+
+	var calls atomic.Int32
+
+	f := func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(int(calls.Add(1)))
+
+		return nil
+	}
+
+	for i := 0; i < 3; i++ {
+		v, err := memo.Get(ctx, "key", f)
+		if err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		if v != 1 {
+			t.Error(v)
+		}
+	}
+
+	<-time.After(60 * time.Millisecond)
+
+	if v, err := memo.Get(ctx, "key", f); err != nil || v != 2 {
+		t.Error(v, err)
+	}
+}
+
+func TestMemoize_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	memo := async.Memoize[string, int](time.Minute)
+
+	var calls atomic.Int32
+
+	f := func(ch chan<- async.Option[int]) error {
+		calls.Add(1)
+
+		return testErr
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := memo.Get(ctx, "key", f); !errors.Is(err, testErr) {
+			t.Error(err)
+		}
+	}
+
+	if n := calls.Load(); n != 2 {
+		t.Error(n)
+	}
+}
@@ -0,0 +1,44 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+var contextKeyMetrics contextKey = "metrics"
+
+// Metrics receives events of tasks. Methods are called concurrently from goroutines of the tasks.
+type Metrics interface {
+	// TaskStarted is called before the task's function is called.
+	TaskStarted(ctx context.Context)
+	// TaskFinished is called after the task's function returned or panicked, err is its error or recovered panic.
+	TaskFinished(ctx context.Context, duration time.Duration, err error)
+	// TaskPanicked is called when panic of the task's function is recovered.
+	TaskPanicked(ctx context.Context)
+	// QueueDepth is called when the number of functions queued in the pool is changed.
+	QueueDepth(ctx context.Context, depth int)
+}
+
+// NoopMetrics ignores all events, it's used by default.
+type NoopMetrics struct{}
+
+func (NoopMetrics) TaskStarted(context.Context)                        {}
+func (NoopMetrics) TaskFinished(context.Context, time.Duration, error) {}
+func (NoopMetrics) TaskPanicked(context.Context)                       {}
+func (NoopMetrics) QueueDepth(context.Context, int)                    {}
+
+// WithMetrics sets metrics for tasks and pools started with the context.
+func WithMetrics(m Metrics) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyMetrics, m)
+	}
+	return fn
+}
+
+func metricsFrom(ctx context.Context) Metrics {
+	if m, _ := ctx.Value(contextKeyMetrics).(Metrics); m != nil {
+		return m
+	}
+
+	return NoopMetrics{}
+}
@@ -0,0 +1,51 @@
+package async_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+type testMetrics struct {
+	async.NoopMetrics
+
+	started, finished, panicked atomic.Int32
+}
+
+func (m *testMetrics) TaskStarted(context.Context) { m.started.Add(1) }
+
+func (m *testMetrics) TaskFinished(context.Context, time.Duration, error) { m.finished.Add(1) }
+
+func (m *testMetrics) TaskPanicked(context.Context) { m.panicked.Add(1) }
+
+func TestWithMetrics(t *testing.T) {
+	m := new(testMetrics)
+
+	// No scope, so the panic doesn't cancel the queued function.
+	ctx := async.WithMetrics(m)(context.Background())
+
+	pool := async.NewPool[int](ctx, 2)
+
+	pool.Submit(
+		func(ch chan<- async.Option[int]) error {
+			return nil
+		},
+	)
+
+	// The panic is buffered, so the task doesn't wait for the consumer.
+	pool.Submit(
+		func(ch chan<- async.Option[int]) error {
+			panic("something went wrong!")
+		},
+		1,
+	)
+
+	pool.Close()
+
+	if m.started.Load() != 2 || m.finished.Load() != 2 || m.panicked.Load() != 1 {
+		t.Error(m.started.Load(), m.finished.Load(), m.panicked.Load())
+	}
+}
@@ -0,0 +1,30 @@
+package async
+
+// Pair is a pair of values of different types.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// MakePair returns the pair of a and b.
+func MakePair[A, B any](a A, b B) Pair[A, B] {
+	return Pair[A, B]{First: a, Second: b}
+}
+
+// Triple is a triple of values of different types.
+type Triple[A, B, C any] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+// MakeTriple returns the triple of a, b and c.
+func MakeTriple[A, B, C any](a A, b B, c C) Triple[A, B, C] {
+	return Triple[A, B, C]{First: a, Second: b, Third: c}
+}
+
+// KV is a key with its value.
+type KV[K comparable, V any] struct {
+	Key   K
+	Value V
+}
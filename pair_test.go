@@ -0,0 +1,31 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestMakeTriple(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.GoValue(ctx, func(ctx context.Context) (async.Triple[string, int, bool], error) {
+		return async.MakeTriple("user", 42, true), nil
+	})
+
+	v, err := async.Await(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v.First != "user" || v.Second != 42 || !v.Third {
+		t.Error(v)
+	}
+
+	if p := async.MakePair("key", 1); p.First != "key" || p.Second != 1 {
+		t.Error(p)
+	}
+}
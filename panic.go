@@ -0,0 +1,91 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+var (
+	contextKeyPanicHandler contextKey = "panicHandler"
+	contextKeyNoRecover    contextKey = "noRecover"
+)
+
+// PanicHandler is called with the value and the stack of every panic recovered inside of a task.
+type PanicHandler func(ctx context.Context, recovered any, stack []byte)
+
+var panicHandler atomic.Pointer[PanicHandler]
+
+// PanicError is the error of a task whose panic was recovered.
+type PanicError struct {
+	// Value is the recovered panic value.
+	Value any
+	// Stack is the stack of the panicked goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered panic: %s:\n%s", e.Value, string(e.Stack))
+}
+
+// Unwrap returns the panic value if it's an error, so errors.Is and errors.As can match it.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
+
+// SetPanicHandler sets the package-level panic handler, nil removes it.
+// The handler is used for tasks whose context has no handler set by WithPanicHandler.
+func SetPanicHandler(h PanicHandler) {
+	if h == nil {
+		panicHandler.Store(nil)
+
+		return
+	}
+
+	panicHandler.Store(&h)
+}
+
+// WithPanicHandler sets the panic handler for tasks started with the context.
+func WithPanicHandler(h PanicHandler) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyPanicHandler, h)
+	}
+	return fn
+}
+
+// WithNoRecover disables recovery of panics of tasks started with the context, so they crash the program
+// with the original stack. Intended for tests and debugging.
+func WithNoRecover() OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyNoRecover, true)
+	}
+	return fn
+}
+
+func noRecover(ctx context.Context) bool {
+	disabled, _ := ctx.Value(contextKeyNoRecover).(bool)
+
+	return disabled
+}
+
+// recovered passes recovered panic value r with the stack of the current goroutine to the panic handler
+// and wraps them to PanicError.
+func recovered(ctx context.Context, r any) error {
+	stack := debug.Stack()
+
+	h, _ := ctx.Value(contextKeyPanicHandler).(PanicHandler)
+	if h == nil {
+		if p := panicHandler.Load(); p != nil {
+			h = *p
+		}
+	}
+
+	if h != nil {
+		h(ctx, r, stack)
+	}
+
+	return &PanicError{Value: r, Stack: stack}
+}
@@ -0,0 +1,124 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestWithPanicHandler(t *testing.T) {
+	var (
+		got   any
+		stack []byte
+	)
+
+	ctx, cancel := async.With(
+		context.Background(),
+		async.WithPanicHandler(func(ctx context.Context, recovered any, s []byte) {
+			got, stack = recovered, s
+		}),
+	)
+	defer cancel()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			panic("something went wrong!")
+		},
+	)
+
+	_, err := async.Await(ctx, ch)
+	if err == nil {
+		t.Fail()
+
+		return
+	}
+
+	if got != "something went wrong!" || len(stack) == 0 {
+		t.Error(got)
+	}
+}
+
+func TestSetPanicHandler(t *testing.T) {
+	called := make(chan any, 1)
+
+	async.SetPanicHandler(func(ctx context.Context, recovered any, stack []byte) {
+		called <- recovered
+	})
+	defer async.SetPanicHandler(nil)
+
+	ch := async.Go(
+		context.Background(),
+		func(ch chan<- async.Option[int]) error {
+			panic(42)
+		},
+		1,
+	)
+
+	_, err := async.Await(context.Background(), ch)
+	if err == nil {
+		t.Fail()
+
+		return
+	}
+
+	if v := <-called; v != 42 {
+		t.Error(v)
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			panic(testErr)
+		},
+	)
+
+	_, err := async.Await(ctx, ch)
+
+	var panicErr *async.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Error(err)
+
+		return
+	}
+
+	if panicErr.Value != testErr || len(panicErr.Stack) == 0 {
+		t.Error(panicErr.Value)
+	}
+
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestWithNoRecover(t *testing.T) {
+	if os.Getenv("ASYNC_TEST_NO_RECOVER") == "1" {
+		ctx := async.WithNoRecover()(context.Background())
+
+		for range async.Go(ctx, func(ch chan<- async.Option[int]) error { panic("something went wrong!") }) {
+		}
+
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWithNoRecover$")
+	cmd.Env = append(os.Environ(), "ASYNC_TEST_NO_RECOVER=1")
+
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || !strings.Contains(string(out), "panic: something went wrong!") {
+		t.Error(err, string(out))
+	}
+}
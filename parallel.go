@@ -0,0 +1,138 @@
+package async
+
+import (
+	"context"
+)
+
+var contextKeyParallelism contextKey = "parallelism"
+
+type parallelism struct {
+	n       int
+	ordered bool
+}
+
+// WithParallelism makes Then and Pipeline.Map started with the context run the mapping function on n goroutines.
+// It also limits the number of concurrently running functions of MapSlice and FilterSlice, which are always ordered.
+// If ordered is true, results keep the order of input values, otherwise they are passed as soon as they are ready.
+func WithParallelism(n int, ordered bool) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyParallelism, parallelism{n: n, ordered: ordered})
+	}
+	return fn
+}
+
+func parallelismFrom(ctx context.Context) (parallelism, bool) {
+	par, ok := ctx.Value(contextKeyParallelism).(parallelism)
+
+	return par, ok && par.n > 1
+}
+
+// thenUnordered runs f over values of the in channel on n workers and passes results to ch as soon as they are ready.
+func thenUnordered[T, U any](ctx context.Context, in <-chan Option[T], f func(T) (U, error), n int, ch chan<- Option[U]) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	worker := func(out chan<- Option[U]) error {
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil || !ok {
+				return nil
+			}
+
+			res := MakeErr[U](opt.Err())
+			if opt.Err() == nil {
+				value, err := f(opt.Value())
+				res = Option[U]{value: value, err: err}
+			}
+
+			select {
+			case out <- res:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+
+	workers := make([]<-chan Option[U], n)
+	for i := range workers {
+		workers[i] = Go(ctx, worker)
+	}
+
+	return forward(ctx, FanIn(ctx, workers...), ch)
+}
+
+// thenOrdered runs f over values of the in channel on n goroutines and passes results to ch in order of the values.
+func thenOrdered[T, U any](ctx context.Context, in <-chan Option[T], f func(T) (U, error), n int, ch chan<- Option[U]) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The result being forwarded and the pending ones are limited to n.
+	pending := make(chan (<-chan Option[U]), n-1)
+
+	go func() {
+		defer close(pending)
+
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil || !ok {
+				return
+			}
+
+			res := Go(ctx, func(out chan<- Option[U]) error {
+				if err := opt.Err(); err != nil {
+					out <- MakeErr[U](err)
+
+					return nil
+				}
+
+				value, err := f(opt.Value())
+				out <- Option[U]{value: value, err: err}
+
+				return nil
+			}, 1)
+
+			select {
+			case pending <- res:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := Go(ctx, func(out chan<- Option[U]) error {
+		for res := range pending {
+			opt, ok, err := recv(ctx, res)
+			if err != nil || !ok {
+				return nil
+			}
+
+			select {
+			case out <- opt:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		return nil
+	})
+
+	return forward(ctx, results, ch)
+}
+
+// forward passes values of the in channel to ch until the first error, which is passed too.
+func forward[T any](ctx context.Context, in <-chan Option[T], ch chan<- Option[T]) error {
+	for {
+		opt, ok, err := recv(ctx, in)
+		if err != nil || !ok {
+			return nil
+		}
+
+		if err := opt.Err(); err != nil {
+			return TrySendError[T](ctx, ch, err)
+		}
+
+		if err := TrySend(ctx, ch, opt.Value()); err != nil {
+			return err
+		}
+	}
+}
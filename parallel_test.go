@@ -0,0 +1,121 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func rangeSource(ctx context.Context, n int) <-chan async.Option[int] {
+	return async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 0; i < n; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+}
+
+func TestWithParallelism_Ordered(t *testing.T) {
+	const testN = 8
+
+	ctx := async.WithParallelism(4, true)(context.Background())
+
+	out := async.Then(ctx, rangeSource(ctx, testN), func(v int) (int, error) {
+		// Later values are ready earlier.
+		<-time.After(time.Duration(testN-v) * 5 * time.Millisecond)
+
+		return v, nil
+	})
+
+	values, err := async.Collect(ctx, out)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(values) != testN {
+		t.Error(values)
+
+		return
+	}
+
+	for i, v := range values {
+		if v != i {
+			t.Error(values)
+
+			return
+		}
+	}
+}
+
+func TestWithParallelism_Unordered(t *testing.T) {
+	const (
+		testN       = 20
+		parallelism = 4
+	)
+
+	var running, peak atomic.Int32
+
+	ctx := async.WithParallelism(parallelism, false)(context.Background())
+
+	out := async.Then(ctx, rangeSource(ctx, testN), func(v int) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		<-time.After(10 * time.Millisecond)
+
+		return v, nil
+	})
+
+	values, err := async.Collect(ctx, out)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(values) != testN {
+		t.Error(values)
+	}
+
+	if p := peak.Load(); p < 2 || p > parallelism {
+		t.Error(p)
+	}
+}
+
+func TestWithParallelism_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	for _, ordered := range []bool{true, false} {
+		ctx := async.WithParallelism(4, ordered)(context.Background())
+
+		out := async.Then(ctx, rangeSource(ctx, 100), func(v int) (int, error) {
+			if v == 10 {
+				return 0, testErr
+			}
+
+			return v, nil
+		})
+
+		_, err := async.Collect(ctx, out)
+		if !errors.Is(err, testErr) {
+			t.Error(ordered, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package async
+
+import (
+	"context"
+)
+
+// Pipeline builds a chain of stages from the source to the sink, wiring the channels between them.
+// The first error of any stage or the sink cancels the whole pipeline and is returned by Run,
+// unless the failed value of a Map stage is passed to the dead letter callback, see DeadLetter.
+type Pipeline[T any] struct {
+	ctx      context.Context
+	source   Func[T]
+	stages   []func(ctx context.Context, in <-chan Option[T]) <-chan Option[T]
+	capacity []int
+	retry    RetryPolicy
+	dead     func(DeadLetter[T])
+	batch    int
+	sink     func([]T) error
+}
+
+// DeadLetter is the value of the pipeline whose processing has failed with Err after all retries.
+type DeadLetter[T any] struct {
+	Value T
+	Err   error
+}
+
+// NewPipeline returns the new empty pipeline started with ctx.
+func NewPipeline[T any](ctx context.Context) *Pipeline[T] {
+	return &Pipeline[T]{ctx: ctx, batch: 1}
+}
+
+// Source sets the function producing values of the pipeline.
+func (p *Pipeline[T]) Source(f Func[T]) *Pipeline[T] {
+	p.source = f
+
+	return p
+}
+
+// Buffer sets the capacity of channels of the source and the stages added after it.
+func (p *Pipeline[T]) Buffer(capacity int) *Pipeline[T] {
+	p.capacity = []int{capacity}
+
+	return p
+}
+
+// Map adds the stage transforming values by f, see Then. The stage's context is modified by opt funcs,
+// e.g. WithParallelism.
+func (p *Pipeline[T]) Map(f func(T) (T, error), opt ...OptFunc) *Pipeline[T] {
+	capacity := p.capacity
+	policy := p.retry
+
+	p.stages = append(p.stages, func(ctx context.Context, in <-chan Option[T]) <-chan Option[T] {
+		for _, o := range opt {
+			if o != nil {
+				ctx = o(ctx)
+			}
+		}
+
+		if p.dead == nil && policy.MaxAttempts <= 1 {
+			return Then(ctx, in, f, capacity...)
+		}
+
+		return compact(ctx, Then(ctx, in, p.process(ctx, f, policy), capacity...), capacity...)
+	})
+
+	return p
+}
+
+// Retry makes the Map stages added after it call their functions again for the values failed with retryable errors.
+// Delays between calls are slept by every value separately, see RetryPolicy.
+func (p *Pipeline[T]) Retry(policy RetryPolicy) *Pipeline[T] {
+	p.retry = policy
+
+	return p
+}
+
+// DeadLetter makes the Map stages pass the values failed after all retries to f along with the error
+// and drop them instead of failing the pipeline. Recovered panics of the stages are passed to f too.
+// f is called concurrently by the stages, so it must be safe for concurrent use.
+func (p *Pipeline[T]) DeadLetter(f func(DeadLetter[T])) *Pipeline[T] {
+	p.dead = f
+
+	return p
+}
+
+// process wraps f to be retried by policy, the values failed then are passed to the dead letter callback
+// and replaced with nil.
+func (p *Pipeline[T]) process(ctx context.Context, f func(T) (T, error), policy RetryPolicy) func(T) (*T, error) {
+	return func(v T) (*T, error) {
+		var out T
+
+		err := policy.do(ctx, func() error {
+			var err error
+
+			out, err = p.call(ctx, f, v)
+
+			return err
+		})
+		if err == nil {
+			return &out, nil
+		}
+
+		if p.dead == nil || ctx.Err() != nil {
+			return nil, err
+		}
+
+		p.dead(DeadLetter[T]{Value: v, Err: err})
+
+		return nil, nil
+	}
+}
+
+// call calls f and converts its recovered panic to the returned error if the pipeline has the dead letter callback.
+func (p *Pipeline[T]) call(ctx context.Context, f func(T) (T, error), v T) (out T, err error) {
+	if p.dead == nil || noRecover(ctx) {
+		return f(v)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ctx, r)
+		}
+	}()
+
+	return f(v)
+}
+
+// compact passes the non-nil values of the in channel to the returned channel.
+// The channel is closed after the in channel is closed. Interrupted by closed context.
+func compact[T any](ctx context.Context, in <-chan Option[*T], capacity ...int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return nil
+			}
+
+			if err := opt.Err(); err != nil {
+				if err := TrySendError[T](ctx, ch, err); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if opt.Value() == nil {
+				continue
+			}
+
+			if err := TrySend(ctx, ch, *opt.Value()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[T](capacity...), in)
+}
+
+// Filter adds the stage passing values satisfying pred, see Filter.
+func (p *Pipeline[T]) Filter(pred func(T) bool) *Pipeline[T] {
+	capacity := p.capacity
+
+	p.stages = append(p.stages, func(ctx context.Context, in <-chan Option[T]) <-chan Option[T] {
+		return Filter(ctx, in, pred, capacity...)
+	})
+
+	return p
+}
+
+// Batch makes the sink receive values in batches of size, the last batch may be incomplete.
+// By default the sink receives values one by one.
+func (p *Pipeline[T]) Batch(size int) *Pipeline[T] {
+	p.batch = max(size, 1)
+
+	return p
+}
+
+// Sink sets the function consuming batches of values of the pipeline, see Batch.
+func (p *Pipeline[T]) Sink(f func([]T) error) *Pipeline[T] {
+	p.sink = f
+
+	return p
+}
+
+// Run starts the pipeline in a new scope and passes its values to the sink until the source is finished.
+// It returns the first error of the pipeline, the rest of the stages are cancelled then.
+// Run of the pipeline without source does nothing.
+func (p *Pipeline[T]) Run() error {
+	if p.source == nil {
+		return nil
+	}
+
+	scope := NewScope(p.ctx)
+	defer scope.Cancel()
+
+	ctx := scope.Context()
+
+	ch := Go(ctx, p.source, p.capacity...)
+
+	for _, stage := range p.stages {
+		ch = stage(ctx, ch)
+	}
+
+	batches := Batch(ctx, ch, p.batch, 0)
+
+	for {
+		opt, ok, err := recv(ctx, batches)
+		if err != nil {
+			if scopeErr := scope.Err(); scopeErr != nil {
+				return scopeErr
+			}
+
+			return err
+		}
+
+		if !ok {
+			return scope.Err()
+		}
+
+		if err := opt.Err(); err != nil {
+			return err
+		}
+
+		if p.sink == nil {
+			continue
+		}
+
+		if err := p.sink(opt.Value()); err != nil {
+			return err
+		}
+	}
+}
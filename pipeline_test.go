@@ -0,0 +1,119 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestPipeline(t *testing.T) {
+	const testN = 10
+
+	var batches [][]int
+
+	err := async.NewPipeline[int](context.Background()).
+		Source(func(ch chan<- async.Option[int]) error {
+			for i := 0; i < testN; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		}).
+		Map(func(v int) (int, error) { return v * 10, nil }).
+		Filter(func(v int) bool { return v%20 == 0 }).
+		Batch(2).
+		Sink(func(batch []int) error {
+			batches = append(batches, batch)
+
+			return nil
+		}).
+		Run()
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(batches) != 3 || len(batches[2]) != 1 || batches[0][1] != 20 || batches[2][0] != 80 {
+		t.Error(batches)
+	}
+}
+
+func TestPipeline_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	err := async.NewPipeline[int](context.Background()).
+		Source(func(ch chan<- async.Option[int]) error {
+			for i := 0; ; i++ {
+				ch <- async.MakeValue(i)
+			}
+		}).
+		Buffer(1).
+		Map(func(v int) (int, error) {
+			if v == 3 {
+				return 0, testErr
+			}
+
+			return v, nil
+		}).
+		Run()
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestPipeline_DeadLetter(t *testing.T) {
+	testErr := errors.New("test error")
+
+	var (
+		attempts int
+		dead     []async.DeadLetter[int]
+		sum      int
+	)
+
+	err := async.NewPipeline[int](context.Background()).
+		Source(func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 4; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		}).
+		Retry(async.RetryPolicy{MaxAttempts: 3}).
+		DeadLetter(func(d async.DeadLetter[int]) { dead = append(dead, d) }).
+		Map(func(v int) (int, error) {
+			switch v {
+			case 2:
+				attempts++
+
+				return 0, testErr
+			case 3:
+				panic("something went wrong!")
+			}
+
+			return v, nil
+		}).
+		Sink(func(batch []int) error {
+			sum += batch[0]
+
+			return nil
+		}).
+		Run()
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if sum != 5 || attempts != 3 {
+		t.Error(sum, attempts)
+	}
+
+	var panicErr *async.PanicError
+
+	if len(dead) != 2 || dead[0].Value != 2 || !errors.Is(dead[0].Err, testErr) || !errors.As(dead[1].Err, &panicErr) {
+		t.Error(dead)
+	}
+}
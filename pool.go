@@ -0,0 +1,219 @@
+package async
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrPoolClosed = errors.New("pool is closed")
+
+var contextKeyPriority contextKey = "priority"
+
+// Priority of the function submitted to the pool. Functions with higher priority are called first.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// Pool runs submitted functions on a fixed number of reusable goroutines.
+type Pool[T any] struct {
+	ctx      context.Context
+	progress *progress
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  poolQueue[T]
+	seq    uint64
+	closed bool
+
+	workers sync.WaitGroup
+	stop    func() bool
+}
+
+type poolTask[T any] struct {
+	ctx      context.Context
+	f        Func[T]
+	ch       chan Option[T]
+	done     func()
+	priority Priority
+	seq      uint64
+}
+
+// NewPool starts size workers. If size is less than 1, the pool has a single worker.
+// When ctx is done, queued functions are not called anymore, their channels are closed with ctx error if it can be sent without blocking.
+func NewPool[T any](ctx context.Context, size int) *Pool[T] {
+	if size < 1 {
+		size = 1
+	}
+
+	ctx, prog := withProgress(ctx, 0)
+
+	p := &Pool[T]{ctx: ctx, progress: prog}
+	p.cond = sync.NewCond(&p.mu)
+
+	p.stop = context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.cond.Broadcast()
+	})
+
+	p.workers.Add(size)
+
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// Submit queues function f to be called by a pool worker. It never blocks.
+// The returned channel is handled the same way as the channel returned by Go.
+// If the pool is closed, the channel receives ErrPoolClosed, if the pool's ctx is done - ctx error.
+func (p *Pool[T]) Submit(f Func[T], capacity ...int) <-chan Option[T] {
+	return p.submit(p.ctx, f, capacity...)
+}
+
+// SubmitWith queues function f like Submit with the pool's context modified by opt funcs,
+// e.g. WithPriority and WithCapacity.
+func (p *Pool[T]) SubmitWith(f Func[T], opt ...OptFunc) <-chan Option[T] {
+	ctx, capacity := applyTask(p.ctx, opt)
+
+	return p.submit(ctx, f, capacity...)
+}
+
+func (p *Pool[T]) submit(ctx context.Context, f Func[T], capacity ...int) <-chan Option[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.ctx.Err() != nil {
+		err := ErrPoolClosed
+		if !p.closed {
+			err = p.ctx.Err()
+		}
+
+		ch := make(chan Option[T], 1)
+		ch <- MakeErr[T](err)
+		close(ch)
+
+		return ch
+	}
+
+	priority, _ := ctx.Value(contextKeyPriority).(Priority)
+
+	ctx, done := track(ctx)
+
+	task := &poolTask[T]{
+		ctx:      ctx,
+		f:        f,
+		ch:       makeChan[T](capacity...),
+		done:     done,
+		priority: priority,
+		seq:      p.seq,
+	}
+
+	p.seq++
+	p.progress.add()
+
+	heap.Push(&p.queue, task)
+	p.cond.Signal()
+
+	metricsFrom(p.ctx).QueueDepth(p.ctx, p.queue.Len())
+
+	return task.ch
+}
+
+// Close stops accepting new functions and waits until the queued ones are done.
+func (p *Pool[T]) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.workers.Wait()
+	p.stop()
+}
+
+func (p *Pool[T]) work() {
+	defer p.workers.Done()
+
+	for {
+		task, ok := p.next()
+		if !ok {
+			return
+		}
+
+		if err := p.ctx.Err(); err != nil {
+			select {
+			case task.ch <- MakeErr[T](err):
+			default:
+			}
+
+			close(task.ch)
+		} else {
+			run(task.ctx, ignoreCtx(task.f), task.ch)
+		}
+
+		task.done()
+		p.progress.finish()
+	}
+}
+
+func (p *Pool[T]) next() (task *poolTask[T], ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.queue) == 0 {
+		if p.closed || p.ctx.Err() != nil {
+			return task, false
+		}
+
+		p.cond.Wait()
+	}
+
+	task = heap.Pop(&p.queue).(*poolTask[T])
+
+	metricsFrom(p.ctx).QueueDepth(p.ctx, p.queue.Len())
+
+	return task, true
+}
+
+// WithPriority sets the priority of the function submitted by Pool.SubmitWith.
+func WithPriority(priority Priority) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyPriority, priority)
+	}
+	return fn
+}
+
+// poolQueue is a priority queue of tasks, tasks of the same priority are kept in submission order.
+type poolQueue[T any] []*poolTask[T]
+
+func (q poolQueue[T]) Len() int { return len(q) }
+
+func (q poolQueue[T]) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q poolQueue[T]) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *poolQueue[T]) Push(x any) { *q = append(*q, x.(*poolTask[T])) }
+
+func (q *poolQueue[T]) Pop() any {
+	old := *q
+	n := len(old)
+	task := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return task
+}
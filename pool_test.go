@@ -0,0 +1,173 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestPool_Submit(t *testing.T) {
+	const testTasks = 100
+	const testSize = 4
+
+	ctx := context.Background()
+
+	pool := async.NewPool[int](ctx, testSize)
+	defer pool.Close()
+
+	var running, maxRunning atomic.Int32
+
+	chans := make([]<-chan async.Option[int], 0, testTasks)
+
+	for i := 0; i < testTasks; i++ {
+		value := i
+
+		chans = append(chans, pool.Submit(
+			func(ch chan<- async.Option[int]) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+
+				ch <- async.MakeValue(value)
+
+				return nil
+			},
+		))
+	}
+
+	for i, ch := range chans {
+		v, err := async.Await(ctx, ch)
+		if err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		if v != i {
+			t.Fail()
+
+			return
+		}
+	}
+
+	if maxRunning.Load() > testSize {
+		t.Error(maxRunning.Load())
+	}
+}
+
+func TestPool_Closed(t *testing.T) {
+	ctx := context.Background()
+
+	pool := async.NewPool[int](ctx, 1)
+	pool.Close()
+
+	ch := pool.Submit(
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+
+			return nil
+		},
+	)
+
+	_, err := async.Await(ctx, ch)
+	if !errors.Is(err, async.ErrPoolClosed) {
+		t.Error(err)
+	}
+}
+
+func TestPool_Panic(t *testing.T) {
+	ctx := context.Background()
+
+	pool := async.NewPool[int](ctx, 1)
+	defer pool.Close()
+
+	ch := pool.Submit(
+		func(ch chan<- async.Option[int]) error {
+			panic("something went wrong!")
+		},
+		1,
+	)
+
+	_, err := async.Await(ctx, ch)
+	if err == nil {
+		t.Fail()
+
+		return
+	}
+
+	// The worker must survive the panic.
+	ch = pool.Submit(
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+
+			return nil
+		},
+	)
+
+	v, err := async.Await(ctx, ch)
+	if err != nil || v != 1 {
+		t.Error(v, err)
+	}
+}
+
+func TestPool_Priority(t *testing.T) {
+	ctx := context.Background()
+
+	pool := async.NewPool[int](ctx, 1)
+	defer pool.Close()
+
+	// Keep the only worker busy while tasks are queued.
+	block := make(chan struct{})
+
+	busy := pool.Submit(
+		func(ch chan<- async.Option[int]) error {
+			<-block
+
+			return nil
+		},
+	)
+
+	var (
+		mu    sync.Mutex
+		order []async.Priority
+		chans []<-chan async.Option[int]
+	)
+
+	for _, p := range []async.Priority{async.PriorityLow, async.PriorityNormal, async.PriorityHigh} {
+		chans = append(chans, pool.SubmitWith(
+			func(ch chan<- async.Option[int]) error {
+				mu.Lock()
+				order = append(order, p)
+				mu.Unlock()
+
+				return nil
+			},
+			async.WithPriority(p),
+		))
+	}
+
+	close(block)
+	<-busy
+
+	for _, ch := range chans {
+		for range ch {
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(order) != 3 || order[0] != async.PriorityHigh || order[1] != async.PriorityNormal || order[2] != async.PriorityLow {
+		t.Error(order)
+	}
+}
@@ -0,0 +1,52 @@
+package async
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+)
+
+var contextKeyProfilerLabels contextKey = "profiler_labels"
+
+// scopeSeq generates ids of scopes.
+var scopeSeq atomic.Uint64
+
+// WithProfilerLabels attaches pprof labels to goroutines of tasks started with the context,
+// so CPU and goroutine profiles can attribute work to the tasks.
+// Label "async.task" is the name of the task set by Named, "async.scope" is the id of the task's scope.
+func WithProfilerLabels() OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyProfilerLabels, true)
+	}
+	return fn
+}
+
+// labeled wraps f to be called with pprof labels of the task if they are enabled by WithProfilerLabels.
+func labeled[T any](ctx context.Context, f FuncCtx[T]) FuncCtx[T] {
+	if enabled, _ := ctx.Value(contextKeyProfilerLabels).(bool); !enabled {
+		return f
+	}
+
+	var labels []string
+
+	if name := nameFrom(ctx); name != "" {
+		labels = append(labels, "async.task", name)
+	}
+
+	if scope := scopeFrom(ctx); scope != nil {
+		labels = append(labels, "async.scope", strconv.FormatUint(scope.id, 10))
+	}
+
+	if len(labels) == 0 {
+		return f
+	}
+
+	return func(ctx context.Context, ch chan<- Option[T]) (err error) {
+		pprof.Do(ctx, pprof.Labels(labels...), func(ctx context.Context) {
+			err = f(ctx, ch)
+		})
+
+		return err
+	}
+}
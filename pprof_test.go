@@ -0,0 +1,35 @@
+package async_test
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestWithProfilerLabels(t *testing.T) {
+	ctx, cancel := async.With(context.Background(), async.WithProfilerLabels(), async.Named("test"))
+	defer cancel()
+
+	ch := async.GoCtx(
+		ctx,
+		func(ctx context.Context, ch chan<- async.Option[string]) error {
+			name, _ := pprof.Label(ctx, "async.task")
+			ch <- async.MakeValue(name)
+
+			return nil
+		},
+	)
+
+	name, err := async.Await(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if name != "test" {
+		t.Error(name)
+	}
+}
@@ -0,0 +1,63 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+var contextKeyProgress contextKey = "progress"
+
+// WithProgress sets the callback reporting progress of Group functions, including GroupN, GroupOrdered,
+// GroupAll and the functions built on them, and of tasks of Pool created with the context.
+// f is called with the numbers of finished and all functions every time one of them finishes, for Pool
+// total is the number of tasks submitted so far. Calls are serialized, so f must not block.
+func WithProgress(f func(done, total int)) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyProgress, f)
+	}
+	return fn
+}
+
+// progress counts finished functions of the group or the pool, see WithProgress.
+type progress struct {
+	mu    sync.Mutex
+	f     func(done, total int)
+	done  int
+	total int
+}
+
+// withProgress returns the progress of total functions if ctx has the callback, otherwise it returns nil.
+// The returned context doesn't have the callback anymore, so the groups built on other groups report once.
+func withProgress(ctx context.Context, total int) (context.Context, *progress) {
+	f, _ := ctx.Value(contextKeyProgress).(func(done, total int))
+	if f == nil {
+		return ctx, nil
+	}
+
+	ctx = context.WithValue(ctx, contextKeyProgress, (func(done, total int))(nil))
+
+	return ctx, &progress{f: f, total: total}
+}
+
+func (p *progress) add() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total++
+}
+
+func (p *progress) finish() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	p.f(p.done, p.total)
+}
@@ -0,0 +1,119 @@
+package async_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+type testProgress struct {
+	mu    sync.Mutex
+	done  []int
+	total int
+}
+
+func (p *testProgress) report(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done = append(p.done, done)
+	p.total = total
+}
+
+func (p *testProgress) last() (done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.done) == 0 {
+		return 0, p.total
+	}
+
+	return p.done[len(p.done)-1], p.total
+}
+
+func TestWithProgress_Group(t *testing.T) {
+	const testN = 10
+
+	g := func(i int) async.Func[int] {
+		return func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(i)
+
+			return nil
+		}
+	}
+
+	for _, group := range []func(ctx context.Context) <-chan async.Option[int]{
+		func(ctx context.Context) <-chan async.Option[int] { return async.Group(ctx, g, testN) },
+		func(ctx context.Context) <-chan async.Option[int] { return async.GroupN(ctx, g, testN, 3) },
+		func(ctx context.Context) <-chan async.Option[int] { return async.GroupOrdered(ctx, g, testN) },
+		func(ctx context.Context) <-chan async.Option[int] { return async.GroupAll(ctx, g, testN) },
+	} {
+		prog := new(testProgress)
+
+		ctx := async.WithProgress(prog.report)(context.Background())
+
+		values, err := async.Collect(context.Background(), group(ctx))
+		if err != nil || len(values) != testN {
+			t.Error(values, err)
+
+			return
+		}
+
+		prog.mu.Lock()
+
+		if len(prog.done) != testN || prog.total != testN {
+			t.Error(prog.done, prog.total)
+		}
+
+		for i, done := range prog.done {
+			if done != i+1 {
+				t.Error(prog.done)
+
+				break
+			}
+		}
+
+		prog.mu.Unlock()
+	}
+}
+
+func TestWithProgress_Pool(t *testing.T) {
+	const testN = 5
+
+	prog := new(testProgress)
+
+	pool := async.NewPool[int](async.WithProgress(prog.report)(context.Background()), 2)
+	defer pool.Close()
+
+	chans := make([]<-chan async.Option[int], testN)
+
+	for i := range chans {
+		chans[i] = pool.Submit(func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(i)
+
+			return nil
+		})
+	}
+
+	if _, err := async.AwaitAll(context.Background(), chans...); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	// The task is reported after its channel is closed.
+	for i := 0; i < 100; i++ {
+		if done, _ := prog.last(); done == testN {
+			break
+		}
+
+		<-time.After(time.Millisecond)
+	}
+
+	if done, total := prog.last(); done != testN || total != testN {
+		t.Error(done, total)
+	}
+}
@@ -0,0 +1,129 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// QueueStore keeps values of the Queue, so a store backed by a database or a file lets them survive restarts.
+// Its methods are called by the producers and the single dispatcher of the queue concurrently.
+type QueueStore[T any] interface {
+	// Push appends v to the tail of the store.
+	Push(ctx context.Context, v T) error
+	// Peek returns the head of the store without removing it, ok is false if the store is empty.
+	Peek(ctx context.Context) (v T, ok bool, err error)
+	// Remove removes the head of the store.
+	Remove(ctx context.Context) error
+}
+
+// Queue is the in-process job queue: producers push values, consumers read them from one channel in FIFO order.
+type Queue[T any] struct {
+	store  QueueStore[T]
+	ch     <-chan Option[T]
+	notify chan struct{}
+}
+
+// NewQueue returns the queue keeping values in store, or in memory if store is nil.
+// Values already kept by the store are passed to the consumers first.
+// The value is removed from the store after it's received by a consumer, so it's delivered again after restart
+// only if the process exits in between. The error of the store stops the queue: it's passed to the channel,
+// then the channel is closed. The channel is also closed after ctx is done.
+func NewQueue[T any](ctx context.Context, store QueueStore[T]) *Queue[T] {
+	if store == nil {
+		store = &memoryStore[T]{}
+	}
+
+	q := &Queue[T]{
+		store:  store,
+		notify: make(chan struct{}, 1),
+	}
+
+	q.ch = GoCtx(ctx, q.dispatch)
+
+	return q
+}
+
+// Push appends v to the queue. It fails if the store fails.
+func (q *Queue[T]) Push(ctx context.Context, v T) error {
+	if err := q.store.Push(ctx, v); err != nil {
+		return err
+	}
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Chan returns the channel of the queued values. Multiple consumers can read it concurrently.
+func (q *Queue[T]) Chan() <-chan Option[T] { return q.ch }
+
+func (q *Queue[T]) dispatch(ctx context.Context, ch chan<- Option[T]) error {
+	for {
+		v, ok, err := q.store.Peek(ctx)
+		if err != nil {
+			_ = TrySendError[T](ctx, ch, err)
+
+			return nil
+		}
+
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-q.notify:
+				continue
+			}
+		}
+
+		if err := TrySend(ctx, ch, v); err != nil {
+			return nil
+		}
+
+		if err := q.store.Remove(ctx); err != nil {
+			_ = TrySendError[T](ctx, ch, err)
+
+			return nil
+		}
+	}
+}
+
+// memoryStore is the default store of the Queue.
+type memoryStore[T any] struct {
+	mu     sync.Mutex
+	values []T
+}
+
+func (s *memoryStore[T]) Push(_ context.Context, v T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = append(s.values, v)
+
+	return nil
+}
+
+func (s *memoryStore[T]) Peek(context.Context) (v T, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.values) == 0 {
+		return v, false, nil
+	}
+
+	return s.values[0], true, nil
+}
+
+func (s *memoryStore[T]) Remove(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var zero T
+
+	s.values[0] = zero
+	s.values = s.values[1:]
+
+	return nil
+}
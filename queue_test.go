@@ -0,0 +1,170 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+type sliceStore struct {
+	mu     sync.Mutex
+	values []string
+}
+
+func (s *sliceStore) Push(_ context.Context, v string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = append(s.values, v)
+
+	return nil
+}
+
+func (s *sliceStore) Peek(context.Context) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.values) == 0 {
+		return "", false, nil
+	}
+
+	return s.values[0], true, nil
+}
+
+func (s *sliceStore) Remove(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = s.values[1:]
+
+	return nil
+}
+
+type failingStore struct {
+	async.QueueStore[int]
+	err error
+}
+
+func (s failingStore) Peek(context.Context) (int, bool, error) { return 0, false, s.err }
+
+func TestQueue(t *testing.T) {
+	const testN = 5
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := async.NewQueue[int](ctx, nil)
+
+	for i := 0; i < testN; i++ {
+		if err := q.Push(ctx, i); err != nil {
+			t.Error(err)
+
+			return
+		}
+	}
+
+	values := make([]int, 0, testN)
+
+	for opt := range q.Chan() {
+		values = append(values, opt.Must())
+
+		if len(values) == testN {
+			break
+		}
+	}
+
+	if !slices.Equal(values, []int{0, 1, 2, 3, 4}) {
+		t.Error(values)
+	}
+
+	cancel()
+
+	for range q.Chan() {
+	}
+}
+
+func TestQueue_Restart(t *testing.T) {
+	scope := async.NewScope(context.Background())
+	ctx := scope.Context()
+
+	mem := &sliceStore{}
+
+	q := async.NewQueue[string](ctx, mem)
+
+	if err := q.Push(ctx, "a"); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if err := q.Push(ctx, "b"); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v, err := async.Await(ctx, q.Chan()); err != nil || v != "a" {
+		t.Error(v, err)
+
+		return
+	}
+
+	// Nobody reads the queue after the cancellation, so "b" stays in the store.
+	scope.Cancel()
+
+	if err := scope.Wait(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	// The new queue continues with values kept by the store.
+	ctx = context.Background()
+
+	q = async.NewQueue[string](ctx, mem)
+
+	if v, err := async.Await(ctx, q.Chan()); err != nil || v != "b" {
+		t.Error(v, err)
+	}
+}
+
+func TestQueue_Redelivery(t *testing.T) {
+	scope := async.NewScope(context.Background())
+
+	mem := &sliceStore{values: []string{"a"}}
+
+	// The dispatcher takes the value, but no consumer receives it before the queue is stopped.
+	async.NewQueue[string](scope.Context(), mem)
+
+	scope.Cancel()
+
+	if err := scope.Wait(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	ctx := context.Background()
+
+	q := async.NewQueue[string](ctx, mem)
+
+	if v, err := async.Await(ctx, q.Chan()); err != nil || v != "a" {
+		t.Error(v, err)
+	}
+}
+
+func TestQueue_StoreErr(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	q := async.NewQueue[int](ctx, failingStore{err: testErr})
+
+	if _, err := async.Await(ctx, q.Chan()); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,28 @@
+package async
+
+import (
+	"context"
+)
+
+var contextKeyRateLimit contextKey = "rateLimit"
+
+// Limiter waits for permission to proceed, e.g. *rate.Limiter of golang.org/x/time/rate.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithRateLimit makes tasks started with the context wait for limiter before their functions are called.
+// If the context is done while waiting, the task fails with the limiter's error.
+// Drivers of Group functions don't wait, only the grouped functions do.
+func WithRateLimit(limiter Limiter) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyRateLimit, limiter)
+	}
+	return fn
+}
+
+func limiterFrom(ctx context.Context) Limiter {
+	limiter, _ := ctx.Value(contextKeyRateLimit).(Limiter)
+
+	return limiter
+}
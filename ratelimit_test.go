@@ -0,0 +1,72 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+type countingLimiter struct {
+	waits atomic.Int32
+	err   error
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits.Add(1)
+
+	return l.err
+}
+
+func TestWithRateLimit(t *testing.T) {
+	const testN = 5
+
+	limiter := &countingLimiter{}
+
+	ctx := async.WithRateLimit(limiter)(context.Background())
+
+	ch := async.Group(
+		ctx,
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				ch <- async.MakeValue(i)
+
+				return nil
+			}
+		},
+		testN,
+	)
+
+	values, err := async.Collect(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(values) != testN {
+		t.Error(values)
+	}
+
+	if n := limiter.waits.Load(); n != testN {
+		t.Error(n)
+	}
+}
+
+func TestWithRateLimit_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := async.WithRateLimit(&countingLimiter{err: testErr})(context.Background())
+
+	ch := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+
+		return nil
+	}, 1)
+
+	if _, err := async.Await(ctx, ch); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
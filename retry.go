@@ -0,0 +1,108 @@
+package async
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	// MaxAttempts is a maximum count of calls including the first one. Zero or less means a single call.
+	MaxAttempts int
+	// Backoff is a delay before the second call.
+	Backoff time.Duration
+	// MaxBackoff limits the delay between calls if it is greater than zero.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every call. Values less than 1 keep the delay constant.
+	Multiplier float64
+	// Jitter randomly shortens every delay by up to the given fraction of it, it is ranged from 0 to 1.
+	Jitter float64
+	// IsRetryable reports whether the call should be repeated after err. If nil, all errors are retryable.
+	IsRetryable func(err error) bool
+}
+
+// Retry runs function f at a new goroutine like Go and calls it again while it returns retryable errors.
+// Recovered panics are treated as returned errors. The last error is handled the same way as in Go.
+// Values sent by f before the failure are not taken back, so f should send after it can't fail anymore.
+func Retry[T any](ctx context.Context, f Func[T], policy RetryPolicy, capacity ...int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		return policy.do(ctx, func() error { return call(ctx, f, ch) })
+	}
+
+	return Go(ctx, fn, capacity...)
+}
+
+// do calls f while it returns retryable errors and returns the last error.
+func (policy RetryPolicy) do(ctx context.Context, f func() error) error {
+	delay := policy.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= policy.MaxAttempts || (policy.IsRetryable != nil && !policy.IsRetryable(err)) {
+			return err
+		}
+
+		if err := sleep(ctx, policy.jitter(delay)); err != nil {
+			return err
+		}
+
+		delay = policy.next(delay)
+	}
+}
+
+func (policy RetryPolicy) next(delay time.Duration) time.Duration {
+	if policy.Multiplier > 1 {
+		delay = time.Duration(float64(delay) * policy.Multiplier)
+	}
+
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+
+	return delay
+}
+
+func (policy RetryPolicy) jitter(delay time.Duration) time.Duration {
+	if policy.Jitter <= 0 {
+		return delay
+	}
+
+	return delay - time.Duration(rand.Float64()*min(policy.Jitter, 1)*float64(delay))
+}
+
+// call calls f at the current goroutine and converts recovered panic to the returned error.
+func call[T any](ctx context.Context, f Func[T], ch chan<- Option[T]) (err error) {
+	if noRecover(ctx) {
+		return f(ch)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ctx, r)
+		}
+	}()
+
+	return f(ch)
+}
+
+// sleep blocks for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := clockFrom(ctx).NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestRetry(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	var calls int
+
+	ch := async.Retry(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			calls++
+			if calls < 3 {
+				return testErr
+			}
+
+			ch <- async.MakeValue(calls)
+
+			return nil
+		},
+		async.RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     time.Millisecond,
+			Multiplier:  2,
+			Jitter:      0.5,
+		},
+	)
+
+	v, err := async.Await(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v != 3 {
+		t.Error(v)
+	}
+}
+
+func TestRetry_NotRetryable(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	var calls int
+
+	ch := async.Retry(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			calls++
+
+			return testErr
+		},
+		async.RetryPolicy{
+			MaxAttempts: 5,
+			IsRetryable: func(err error) bool { return !errors.Is(err, testErr) },
+		},
+		1,
+	)
+
+	_, err := async.Await(ctx, ch)
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if calls != 1 {
+		t.Error(calls)
+	}
+}
+
+func TestRetry_Panic(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int
+
+	ch := async.Retry(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			calls++
+
+			panic("something went wrong!")
+		},
+		async.RetryPolicy{MaxAttempts: 3},
+		1,
+	)
+
+	_, err := async.Await(ctx, ch)
+	if err == nil {
+		t.Fail()
+	}
+
+	if calls != 3 {
+		t.Error(calls)
+	}
+}
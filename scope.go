@@ -0,0 +1,509 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrWaitTimeout = errors.New("wait timeout")
+
+// maxFailures limits the number of failures kept by the scope for the channels which can't receive them.
+const maxFailures = 1024
+
+// failedScopes maps channels with kept failures to their scopes, so Abandon can drop the failure.
+var failedScopes sync.Map
+
+var (
+	contextKeyErrors        contextKey = "errors"
+	contextKeyErrorOverflow contextKey = "error_overflow"
+	contextKeyCollectErrors contextKey = "collect_errors"
+)
+
+// OverflowPolicy defines what happens to a new element when the channel is full:
+// the scope's errors channel, see WithErrors, or the channel of the Sender, see WithBackpressure.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the new element.
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest buffered element to make room for the new one.
+	OverflowDropOldest
+	// OverflowBlock blocks the sender until the new element is read from the channel.
+	OverflowBlock
+	// OverflowError makes Sender fail with ErrChannelFull. The errors channel of the scope drops the new error.
+	OverflowError
+)
+
+type errorsConfig struct {
+	size   int
+	policy OverflowPolicy
+}
+
+// Scope is a group of tasks sharing cancellation and the first error.
+// Tasks are started by Scope.Go or by Go with the scope's context, so Go and Group calls
+// made inside of the tasks belong to the same scope.
+// The first failed task cancels the scope's context, while its error is received from its own channel.
+// The scope created with CollectErrors isn't cancelled by failed tasks.
+type Scope struct {
+	id     uint64
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	parent *Scope
+
+	wg sync.WaitGroup
+
+	mu       sync.Mutex
+	err      error
+	failures map[any]error
+	// failed are the channels of failures in the order they were kept, see maxFailures.
+	failed []any
+	// first is the channel of the task which failed first.
+	first any
+	// links map channels of running stages to the channels they read, see link.
+	links map[any][]any
+
+	collect bool
+	all     []error
+
+	// tasks are running tasks, the done ones are kept only in debug mode, see WithDebug.
+	tasks    []*TaskInfo
+	debug    bool
+	cleanups []func(ctx context.Context) error
+
+	errs       chan error
+	policy     OverflowPolicy
+	onOverflow func(ctx context.Context, err error)
+
+	maxTasks int
+	running  int
+	queue    []*queuedTask
+}
+
+// NewScope returns the new scope with the context derived from ctx and modified by opt funcs.
+func NewScope(ctx context.Context, opt ...OptFunc) *Scope {
+	scope := &Scope{
+		id:       scopeSeq.Add(1),
+		failures: make(map[any]error),
+		links:    make(map[any][]any),
+	}
+
+	ctx, scope.cancel = context.WithCancelCause(ctx)
+
+	ctx = context.WithValue(ctx, contextKeyScope, scope)
+
+	for _, o := range opt {
+		if o != nil {
+			ctx = o(ctx)
+		}
+	}
+
+	scope.ctx = ctx
+
+	cfg := errorsConfig{size: 1}
+	if c, ok := ctx.Value(contextKeyErrors).(errorsConfig); ok {
+		cfg = c
+	}
+
+	scope.errs = make(chan error, cfg.size)
+	scope.policy = cfg.policy
+	scope.onOverflow, _ = ctx.Value(contextKeyErrorOverflow).(func(context.Context, error))
+	scope.maxTasks, _ = ctx.Value(contextKeyMaxTasks).(int)
+	scope.collect, _ = ctx.Value(contextKeyCollectErrors).(bool)
+	scope.debug, _ = ctx.Value(contextKeyDebug).(bool)
+
+	return scope
+}
+
+// Child returns the new scope nested in s with the context derived from the scope's context and modified by opt funcs.
+// The child is cancelled when s is cancelled, and s waits for the tasks of the child in Wait and Shutdown.
+// The child can be cancelled independently, its failed tasks don't fail s.
+// Tasks of the child share the limit of s set by WithMaxTasks, the child takes its own limit
+// only from opt funcs, then its tasks are limited by both.
+func (s *Scope) Child(opt ...OptFunc) *Scope {
+	// The limit of s isn't inherited as the child's own one, spawn takes the slots of parents instead.
+	child := NewScope(context.WithValue(s.ctx, contextKeyMaxTasks, 0), opt...)
+	child.parent = s
+
+	return child
+}
+
+// Context returns the scope's context.
+func (s *Scope) Context() context.Context { return s.ctx }
+
+// Cancel cancels the scope's context.
+func (s *Scope) Cancel() { s.cancel(nil) }
+
+// Go runs function f at a new goroutine tracked by the scope. f receives the context of its task,
+// so Defer and Heartbeat work with it. Error returned by f or recovered panic cancels the scope,
+// unless the scope collects errors.
+func (s *Scope) Go(f func(ctx context.Context) error) {
+	GoCtx(s.ctx, func(ctx context.Context, _ chan<- Option[struct{}]) error { return f(ctx) }, 1)
+}
+
+// Wait blocks until all tasks of the scope are done and returns the first error of them,
+// or all errors joined by errors.Join for the scope collecting errors, see CollectErrors.
+func (s *Scope) Wait() error {
+	s.wg.Wait()
+
+	return s.Err()
+}
+
+// WaitTimeout waits for the scope's tasks like Wait, but no longer than d.
+// ErrWaitTimeout is returned when d expires, the tasks keep running.
+func (s *Scope) WaitTimeout(d time.Duration) error {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		s.wg.Wait()
+	}()
+
+	timer := clockFrom(s.ctx).NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return s.Err()
+	case <-timer.C():
+		return ErrWaitTimeout
+	}
+}
+
+// Err returns the first error of the scope's tasks or nil if none of them failed yet.
+// For the scope collecting errors it returns all errors joined by errors.Join, see CollectErrors.
+func (s *Scope) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.collect {
+		return errors.Join(s.all...)
+	}
+
+	return s.err
+}
+
+// Tasks returns the snapshot of running tasks of the scope in the start order.
+// The scope created with WithDebug keeps done tasks too, so it returns all tasks started at the scope.
+func (s *Scope) Tasks() []TaskInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]TaskInfo, len(s.tasks))
+	for i, task := range s.tasks {
+		tasks[i] = *task
+	}
+
+	return tasks
+}
+
+// NumGoroutine returns the number of running tasks of the scope.
+func (s *Scope) NumGoroutine() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.debug {
+		return len(s.tasks)
+	}
+
+	n := 0
+
+	for _, task := range s.tasks {
+		if task.State == TaskRunning {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s *Scope) register(name string) *TaskInfo {
+	task := &TaskInfo{Name: name, State: TaskRunning, Started: clockFrom(s.ctx).Now()}
+
+	s.mu.Lock()
+	s.tasks = append(s.tasks, task)
+	s.mu.Unlock()
+
+	return task
+}
+
+func (s *Scope) finish(task *TaskInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.State = TaskDone
+	task.Finished = clockFrom(s.ctx).Now()
+
+	if s.debug {
+		return
+	}
+
+	if i := slices.Index(s.tasks, task); i >= 0 {
+		s.tasks = slices.Delete(s.tasks, i, i+1)
+	}
+}
+
+// FailFast makes the first failed task cancel the new scope, see NewScope and With. It's the default policy.
+func FailFast() OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyCollectErrors, false)
+	}
+	return fn
+}
+
+// CancelOnError is the same as FailFast: the first failed task cancels the new scope.
+// Use CollectErrors for the scope whose failed tasks don't cancel the others.
+func CancelOnError() OptFunc { return FailFast() }
+
+// CollectErrors makes the new scope keep running its tasks to completion when some of them fail,
+// see NewScope and With. Scope.Wait and Scope.Err return errors of all failed tasks joined by errors.Join.
+func CollectErrors() OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyCollectErrors, true)
+	}
+	return fn
+}
+
+// Errors returns the channel receiving errors of all failed tasks of the scope, including the first one.
+// The channel is never closed, its size and overflow policy are set by WithErrors.
+func (s *Scope) Errors() <-chan error { return s.errs }
+
+// setFailure keeps err of the task whose channel ch has no room for it.
+// Failures of the channels nobody receives from are kept up to maxFailures, then the oldest are dropped.
+func (s *Scope) setFailure(ch any, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[ch] = err
+	s.failed = append(s.failed, ch)
+
+	failedScopes.Store(ch, s)
+
+	for len(s.failures) > maxFailures {
+		delete(s.failures, s.failed[0])
+		failedScopes.Delete(s.failed[0])
+
+		s.failed[0] = nil
+		s.failed = s.failed[1:]
+	}
+
+	// Channels of the taken failures are left in the order, it's compacted when they prevail.
+	if len(s.failed) > 2*max(len(s.failures), maxFailures/2) {
+		s.failed = slices.DeleteFunc(s.failed, func(ch any) bool {
+			_, ok := s.failures[ch]
+
+			return !ok
+		})
+	}
+}
+
+// takeFailure returns and forgets the failure of the task of the ch channel.
+func (s *Scope) takeFailure(ch any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err, ok := s.failures[ch]
+	if ok {
+		delete(s.failures, ch)
+		failedScopes.Delete(ch)
+	}
+
+	return err
+}
+
+// forgetFailure drops the failure kept for the ch channel, see Abandon.
+func forgetFailure(ch any) {
+	if s, ok := failedScopes.Load(ch); ok {
+		s.(*Scope).takeFailure(ch)
+	}
+}
+
+// link records that the stage of the out channel reads the ins channels, so the consumer of out cancelled
+// by the failure of the upstream task receives its error instead of the cancellation, see upstreamFailure.
+func (s *Scope) link(out any, ins ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.links[out] = append(s.links[out], ins...)
+}
+
+// unlink forgets the links of the finished stage of the out channel.
+// They are kept once the scope has failed, the consumer of out may still need them.
+func (s *Scope) unlink(out any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		delete(s.links, out)
+	}
+}
+
+// upstreamFailure returns the first error of the scope if the task which failed first feeds the stage of the ch channel
+// directly or through other stages, see link. The failure of the ch channel's own task is not reported.
+func (s *Scope) upstreamFailure(ch any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.first == nil || s.first == ch {
+		return nil
+	}
+
+	seen := map[any]bool{ch: true}
+	next := []any{ch}
+
+	for len(next) > 0 {
+		out := next[len(next)-1]
+		next = next[:len(next)-1]
+
+		for _, in := range s.links[out] {
+			if in == s.first {
+				return s.err
+			}
+
+			if !seen[in] {
+				seen[in] = true
+				next = append(next, in)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fail records the first error and the ch channel of its task and cancels the scope with err as the cause.
+// Then err is passed to the errors channel, the error dropped by it is passed to the overflow callback.
+func (s *Scope) fail(ch any, err error) {
+	s.mu.Lock()
+	first := s.err == nil
+	if first {
+		s.err = err
+		s.first = ch
+	}
+	if s.collect {
+		s.all = append(s.all, err)
+	}
+	s.mu.Unlock()
+
+	if !s.collect {
+		s.cancel(err)
+	}
+
+	if s.push(err) || first {
+		return
+	}
+
+	if s.onOverflow != nil {
+		s.onOverflow(s.ctx, err)
+	}
+}
+
+// push passes err to the errors channel following the overflow policy.
+// The errors dropped from the head of the channel are passed to the overflow callback.
+func (s *Scope) push(err error) (ok bool) {
+	switch s.policy {
+	case OverflowBlock:
+		s.errs <- err
+
+		return true
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.errs <- err:
+				return true
+			default:
+			}
+
+			select {
+			case old := <-s.errs:
+				if s.onOverflow != nil {
+					s.onOverflow(s.ctx, old)
+				}
+			default:
+				if cap(s.errs) == 0 {
+					return false
+				}
+			}
+		}
+
+	default:
+		select {
+		case s.errs <- err:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// Err returns the first error or recovered panic of tasks of the ctx's scope created by With or NewScope.
+// The error is kept after all channels are drained. It returns nil if the scope has not failed or ctx has no scope.
+func Err(ctx context.Context) error {
+	if scope := scopeFrom(ctx); scope != nil {
+		return scope.Err()
+	}
+
+	return nil
+}
+
+// Cause returns the cause of the ctx cancellation like context.Cause. If ctx is cancelled by the failure of a task
+// of its scope, the cause is the task's error, so sibling tasks can tell it from the bare context.Canceled.
+func Cause(ctx context.Context) error {
+	return context.Cause(ctx)
+}
+
+// Errors returns the errors channel of the ctx's scope, see Scope.Errors. It returns nil if ctx has no scope.
+func Errors(ctx context.Context) <-chan error {
+	if scope := scopeFrom(ctx); scope != nil {
+		return scope.Errors()
+	}
+
+	return nil
+}
+
+// WaitAll blocks until all tasks of the ctx's scope are done and returns the first error of them.
+// It returns nil immediately if ctx has no scope.
+func WaitAll(ctx context.Context) error {
+	if scope := scopeFrom(ctx); scope != nil {
+		return scope.Wait()
+	}
+
+	return nil
+}
+
+// WaitAllTimeout waits for the tasks of the ctx's scope like WaitAll, but no longer than d.
+// ErrWaitTimeout is returned when d expires, the tasks keep running.
+func WaitAllTimeout(ctx context.Context, d time.Duration) error {
+	if scope := scopeFrom(ctx); scope != nil {
+		return scope.WaitTimeout(d)
+	}
+
+	return nil
+}
+
+// WithErrors sets the size and the overflow policy of the errors channel of the scope created by With or NewScope.
+// By default the channel keeps a single error and drops the newer ones, see WithErrorOverflow.
+func WithErrors(size int, policy OverflowPolicy) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyErrors, errorsConfig{size: max(size, 0), policy: policy})
+	}
+	return fn
+}
+
+// WithErrorOverflow sets the callback receiving errors dropped from the scope's errors channel, otherwise they are
+// dropped silently. The first error of the scope is kept by Scope.Err, so it's not passed if the channel has no room for it.
+func WithErrorOverflow(f func(ctx context.Context, err error)) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyErrorOverflow, f)
+	}
+	return fn
+}
+
+func scopeFrom(ctx context.Context) *Scope {
+	scope, _ := ctx.Value(contextKeyScope).(*Scope)
+
+	return scope
+}
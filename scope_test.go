@@ -0,0 +1,514 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestScope_Wait(t *testing.T) {
+	const testN = 10
+
+	scope := async.NewScope(context.Background())
+
+	var count atomic.Int32
+
+	for i := 0; i < testN; i++ {
+		scope.Go(func(ctx context.Context) error {
+			count.Add(1)
+
+			return nil
+		})
+	}
+
+	if err := scope.Wait(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if count.Load() != testN {
+		t.Error(count.Load())
+	}
+}
+
+func TestScope_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background())
+
+	scope.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return nil
+	})
+
+	scope.Go(func(ctx context.Context) error {
+		return testErr
+	})
+
+	err := scope.Wait()
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if !errors.Is(scope.Err(), testErr) {
+		t.Error(scope.Err())
+	}
+
+	if !errors.Is(scope.Context().Err(), context.Canceled) {
+		t.Error(scope.Context().Err())
+	}
+}
+
+func TestScope_GoTracked(t *testing.T) {
+	scope := async.NewScope(context.Background())
+
+	var done atomic.Bool
+
+	ch := async.Go(
+		scope.Context(),
+		func(ch chan<- async.Option[int]) error {
+			done.Store(true)
+
+			return nil
+		},
+	)
+
+	if err := scope.Wait(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if !done.Load() {
+		t.Fail()
+	}
+
+	_, err := async.Await(context.Background(), ch)
+	if !errors.Is(err, async.ErrChannelClosed) {
+		t.Error(err)
+	}
+}
+
+func TestScope_GoDefer(t *testing.T) {
+	scope := async.NewScope(context.Background())
+
+	var deferred atomic.Bool
+
+	scope.Go(func(ctx context.Context) error {
+		async.Defer(ctx, func() { deferred.Store(true) })
+
+		return nil
+	})
+
+	if err := scope.Wait(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	// f receives the context of its own task, so the deferred func is called after it returns.
+	if !deferred.Load() {
+		t.Fail()
+	}
+}
+
+func TestScope_AwaitAttribution(t *testing.T) {
+	const testAwaits = 5
+
+	testErr := errors.New("test error")
+
+	ctx, cancel := async.With(context.Background())
+	defer cancel()
+
+	block := make(chan struct{})
+	defer close(block)
+
+	chans := make([]<-chan async.Option[int], testAwaits)
+
+	for i := range chans {
+		chans[i] = async.Go(
+			ctx,
+			func(ch chan<- async.Option[int]) error {
+				<-block
+
+				return nil
+			},
+		)
+	}
+
+	failed := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			return testErr
+		},
+	)
+
+	errs := make(chan error, testAwaits)
+
+	for _, ch := range chans {
+		go func(ch <-chan async.Option[int]) {
+			_, err := async.Await(ctx, ch)
+			errs <- err
+		}(ch)
+	}
+
+	// Awaits of other tasks are cancelled, but don't get the error of the failed task.
+	for range chans {
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Error(err)
+		}
+	}
+
+	if _, err := async.Await(ctx, failed); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if err := async.Err(ctx); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestErr(t *testing.T) {
+	ctx, cancel := async.With(context.Background())
+	defer cancel()
+
+	if err := async.Err(ctx); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			panic("something went wrong!")
+		},
+	)
+
+	// Nobody awaits the error, it's only drained.
+	for range ch {
+	}
+
+	if err := async.Err(ctx); err == nil {
+		t.Fail()
+	}
+
+	if err := async.Err(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWaitAll(t *testing.T) {
+	const testN = 5
+
+	ctx, cancel := async.With(context.Background())
+	defer cancel()
+
+	var count atomic.Int32
+
+	for i := 0; i < testN; i++ {
+		async.Go(
+			ctx,
+			func(ch chan<- async.Option[int]) error {
+				<-time.After(10 * time.Millisecond)
+				count.Add(1)
+
+				return nil
+			},
+		)
+	}
+
+	if err := async.WaitAll(ctx); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if count.Load() != testN {
+		t.Error(count.Load())
+	}
+}
+
+func TestWaitAllTimeout(t *testing.T) {
+	ctx, cancel := async.With(context.Background())
+	defer cancel()
+
+	async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			<-time.After(10 * time.Second)
+
+			return nil
+		},
+	)
+
+	err := async.WaitAllTimeout(ctx, 20*time.Millisecond)
+	if !errors.Is(err, async.ErrWaitTimeout) {
+		t.Error(err)
+	}
+}
+
+func TestScope_Errors(t *testing.T) {
+	const testN = 5
+
+	scope := async.NewScope(context.Background(), async.WithErrors(testN, async.OverflowDropNewest))
+
+	for i := 0; i < testN; i++ {
+		scope.Go(func(ctx context.Context) error {
+			return errors.New("test error")
+		})
+	}
+
+	if err := scope.Wait(); err == nil {
+		t.Fail()
+
+		return
+	}
+
+	if n := len(scope.Errors()); n != testN {
+		t.Error(n)
+	}
+}
+
+func TestScope_ErrorsDropOldest(t *testing.T) {
+	errs := []error{errors.New("error 0"), errors.New("error 1"), errors.New("error 2")}
+
+	var dropped []error
+
+	ctx, cancel := async.With(
+		context.Background(),
+		async.WithErrors(1, async.OverflowDropOldest),
+		async.WithErrorOverflow(func(ctx context.Context, err error) { dropped = append(dropped, err) }),
+	)
+	defer cancel()
+
+	for _, err := range errs {
+		for range async.Go(ctx, func(ch chan<- async.Option[int]) error { return err }) {
+		}
+	}
+
+	if len(dropped) != 2 || !errors.Is(dropped[0], errs[0]) || !errors.Is(dropped[1], errs[1]) {
+		t.Error(dropped)
+
+		return
+	}
+
+	if err := <-async.Errors(ctx); !errors.Is(err, errs[2]) {
+		t.Error(err)
+	}
+}
+
+func TestCause(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background())
+
+	causes := make(chan error, 1)
+
+	scope.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		causes <- async.Cause(ctx)
+
+		return nil
+	})
+
+	scope.Go(func(ctx context.Context) error {
+		return testErr
+	})
+
+	if err := scope.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if err := <-causes; !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+
+	if err := async.Cause(scope.Context()); !errors.Is(err, testErr) || !errors.Is(scope.Context().Err(), context.Canceled) {
+		t.Error(err)
+	}
+}
+
+func TestScope_CollectErrors(t *testing.T) {
+	errs := []error{errors.New("error 0"), errors.New("error 1")}
+
+	scope := async.NewScope(context.Background(), async.CollectErrors(), async.WithErrors(len(errs), async.OverflowDropNewest))
+
+	var done atomic.Bool
+
+	scope.Go(func(ctx context.Context) error {
+		<-time.After(20 * time.Millisecond)
+		done.Store(ctx.Err() == nil)
+
+		return nil
+	})
+
+	for _, err := range errs {
+		scope.Go(func(ctx context.Context) error { return err })
+	}
+
+	err := scope.Wait()
+	if !errors.Is(err, errs[0]) || !errors.Is(err, errs[1]) {
+		t.Error(err)
+	}
+
+	// Failed tasks don't cancel the others.
+	if !done.Load() {
+		t.Fail()
+	}
+}
+
+func TestScope_CollectErrorsDelivered(t *testing.T) {
+	const testN = 3
+
+	testErr := errors.New("test error")
+
+	logger := new(testLogger)
+
+	scope := async.NewScope(context.Background(), async.CollectErrors(), async.WithLogger(logger))
+
+	for i := 0; i < testN; i++ {
+		ch := async.Go(scope.Context(), func(ch chan<- async.Option[int]) error { return testErr }, 1)
+
+		if _, err := async.Await(scope.Context(), ch); !errors.Is(err, testErr) {
+			t.Error(err)
+
+			return
+		}
+	}
+
+	if err := scope.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+
+		return
+	}
+
+	// The errors received from the channels are not lost, even if the errors channel of the scope is full.
+	if logger.len() != 0 {
+		t.Error(logger.msgs)
+	}
+}
+
+func TestScope_FailureAbandoned(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background(), async.CollectErrors())
+
+	chans := make([]<-chan async.Option[int], 2)
+
+	for i := range chans {
+		chans[i] = async.Go(scope.Context(), func(ch chan<- async.Option[int]) error { return testErr })
+	}
+
+	if err := scope.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+
+		return
+	}
+
+	// The failure kept for the unbuffered channel is dropped when the channel is abandoned.
+	async.Abandon(chans[0])
+
+	if _, err := async.Await(scope.Context(), chans[0]); !errors.Is(err, async.ErrChannelClosed) {
+		t.Error(err)
+
+		return
+	}
+
+	if _, err := async.Await(scope.Context(), chans[1]); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestScope_FailuresLimit(t *testing.T) {
+	const testN = 2000
+
+	testErr := errors.New("test error")
+
+	// Tasks are run one by one, so their failures are kept in order.
+	scope := async.NewScope(context.Background(), async.CollectErrors(), async.WithMaxTasks(1))
+
+	chans := make([]<-chan async.Option[int], testN)
+
+	for i := range chans {
+		chans[i] = async.Go(scope.Context(), func(ch chan<- async.Option[int]) error { return testErr })
+	}
+
+	if err := scope.Wait(); !errors.Is(err, testErr) {
+		t.Error(err)
+
+		return
+	}
+
+	// The oldest failures of the channels nobody receives from are dropped.
+	if _, err := async.Await(scope.Context(), chans[0]); !errors.Is(err, async.ErrChannelClosed) {
+		t.Error(err)
+
+		return
+	}
+
+	if _, err := async.Await(scope.Context(), chans[testN-1]); !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestScope_FailFast(t *testing.T) {
+	testErr := errors.New("test error")
+
+	for _, opt := range []async.OptFunc{async.FailFast(), async.CancelOnError()} {
+		scope := async.NewScope(context.Background(), async.CollectErrors(), opt)
+
+		scope.Go(func(ctx context.Context) error { return testErr })
+
+		if err := scope.Wait(); !errors.Is(err, testErr) {
+			t.Error(err)
+		}
+
+		if !errors.Is(scope.Context().Err(), context.Canceled) {
+			t.Error(scope.Context().Err())
+		}
+	}
+}
+
+func TestScope_Child(t *testing.T) {
+	testErr := errors.New("test error")
+
+	parent := async.NewScope(context.Background())
+	child := parent.Child()
+
+	var done atomic.Bool
+
+	child.Go(func(ctx context.Context) error {
+		<-time.After(20 * time.Millisecond)
+		done.Store(true)
+
+		return testErr
+	})
+
+	// The parent waits for the tasks of the child, but doesn't fail with them.
+	if err := parent.Wait(); err != nil || !done.Load() {
+		t.Error(err, done.Load())
+
+		return
+	}
+
+	if !errors.Is(child.Err(), testErr) || parent.Context().Err() != nil {
+		t.Error(child.Err(), parent.Context().Err())
+
+		return
+	}
+
+	other := parent.Child()
+
+	parent.Cancel()
+
+	if !errors.Is(other.Context().Err(), context.Canceled) {
+		t.Error(other.Context().Err())
+	}
+}
@@ -0,0 +1,56 @@
+package async
+
+import (
+	"context"
+)
+
+// Select2 reads the chA and chB channels of different types until both of them are closed
+// and passes every received option to the onA or onB callback of its channel.
+// It returns ctx error if the context is done before the channels are closed.
+func Select2[A, B any](ctx context.Context, chA <-chan Option[A], chB <-chan Option[B], onA func(Option[A]), onB func(Option[B])) error {
+	return Select3[A, B, struct{}](ctx, chA, chB, nil, onA, onB, nil)
+}
+
+// Select3 reads the chA, chB and chC channels of different types until all of them are closed like Select2.
+// Nil channels are treated as closed ones.
+func Select3[A, B, C any](
+	ctx context.Context,
+	chA <-chan Option[A], chB <-chan Option[B], chC <-chan Option[C],
+	onA func(Option[A]), onB func(Option[B]), onC func(Option[C]),
+) error {
+	scope := scopeFrom(ctx)
+
+	for chA != nil || chB != nil || chC != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case opt, ok := <-chA:
+			chA = selected(scope, chA, opt, ok, onA)
+
+		case opt, ok := <-chB:
+			chB = selected(scope, chB, opt, ok, onB)
+
+		case opt, ok := <-chC:
+			chC = selected(scope, chC, opt, ok, onC)
+		}
+	}
+
+	return nil
+}
+
+// selected passes the received option to on. It returns nil instead of the closed ch channel
+// after passing the failure of its task kept by the scope.
+func selected[T any](scope *Scope, ch <-chan Option[T], opt Option[T], ok bool, on func(Option[T])) <-chan Option[T] {
+	if ok {
+		on(opt)
+
+		return ch
+	}
+
+	if opt, ok := takeFailure(scope, ch); ok {
+		on(opt)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestSelect2(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	chA := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		for i := 1; i <= 3; i++ {
+			ch <- async.MakeValue(i)
+		}
+
+		return nil
+	})
+
+	chB := async.Go(ctx, func(ch chan<- async.Option[string]) error {
+		ch <- async.MakeValue("b")
+
+		return testErr
+	}, 2)
+
+	var (
+		sum  int
+		strs []string
+		errs []error
+	)
+
+	err := async.Select2(
+		ctx, chA, chB,
+		func(opt async.Option[int]) { sum += opt.Value() },
+		func(opt async.Option[string]) {
+			if err := opt.Err(); err != nil {
+				errs = append(errs, err)
+
+				return
+			}
+
+			strs = append(strs, opt.Value())
+		},
+	)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if sum != 6 || len(strs) != 1 || len(errs) != 1 || !errors.Is(errs[0], testErr) {
+		t.Error(sum, strs, errs)
+	}
+}
+
+func TestSelect3_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	block := make(chan async.Option[int])
+
+	err := async.Select3[int, int, int](ctx, block, nil, nil, func(async.Option[int]) {}, nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,65 @@
+package async
+
+import (
+	"context"
+)
+
+var contextKeySemaphore contextKey = "semaphore"
+
+// Semaphore limits the number of concurrently running tasks sharing it.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// NewSemaphore returns the semaphore with n slots. If n is less than 1, the semaphore has a single slot.
+func NewSemaphore(n int) *Semaphore {
+	if n < 1 {
+		n = 1
+	}
+
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+// Acquire blocks until a slot is free or ctx is done.
+func (sem *Semaphore) Acquire(ctx context.Context) error {
+	select {
+	case sem.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot taken by Acquire.
+func (sem *Semaphore) Release() { <-sem.slots }
+
+// WithSemaphore makes tasks started with the context take a slot of sem before their functions are called.
+// Goroutines of the tasks are started immediately, but their functions wait for a free slot.
+// If the context is done while waiting, the task fails with ctx error.
+// Drivers of Group functions don't take slots, only the grouped functions do.
+func WithSemaphore(sem *Semaphore) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeySemaphore, sem)
+	}
+	return fn
+}
+
+func semaphoreFrom(ctx context.Context) *Semaphore {
+	sem, _ := ctx.Value(contextKeySemaphore).(*Semaphore)
+
+	return sem
+}
+
+// withoutLimits hides the semaphore, the rate limiter and the scope's task limit of ctx
+// from tasks which only drive other tasks.
+func withoutLimits(ctx context.Context) context.Context {
+	if semaphoreFrom(ctx) != nil {
+		ctx = context.WithValue(ctx, contextKeySemaphore, (*Semaphore)(nil))
+	}
+
+	if limiterFrom(ctx) != nil {
+		ctx = context.WithValue(ctx, contextKeyRateLimit, nil)
+	}
+
+	return withoutQueue(ctx)
+}
@@ -0,0 +1,84 @@
+package async_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestGoWith_Semaphore(t *testing.T) {
+	const testTasks = 50
+	const testSlots = 3
+
+	ctx := context.Background()
+
+	sem := async.NewSemaphore(testSlots)
+
+	var running, maxRunning atomic.Int32
+
+	chans := make([]<-chan async.Option[int], 0, testTasks)
+
+	for i := 0; i < testTasks; i++ {
+		chans = append(chans, async.GoWith(
+			ctx,
+			func(ch chan<- async.Option[int]) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+
+				ch <- async.MakeValue(1)
+
+				return nil
+			},
+			async.WithSemaphore(sem),
+			async.WithCapacity(1),
+		))
+	}
+
+	if _, err := async.AwaitAll(ctx, chans...); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if maxRunning.Load() > testSlots {
+		t.Error(maxRunning.Load())
+	}
+}
+
+func TestWithSemaphore_Group(t *testing.T) {
+	const testN = 10
+
+	ctx := async.WithSemaphore(async.NewSemaphore(1))(context.Background())
+
+	ch := async.Group(
+		ctx,
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				ch <- async.MakeValue(1)
+
+				return nil
+			}
+		},
+		testN,
+	)
+
+	sum, err := async.Reduce(ctx, ch, 0, func(acc, v int) (int, error) { return acc + v, nil })
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if sum != testN {
+		t.Error(sum)
+	}
+}
@@ -0,0 +1,123 @@
+package async
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrChannelFull = errors.New("channel is full")
+
+var contextKeyBackpressure contextKey = "backpressure"
+
+// FuncSender is a callback writing to the task's channel through the Sender.
+type FuncSender[T any] func(ctx context.Context, s Sender[T]) error
+
+// Sender writes options to the channel of the task started by GoSender.
+// Unlike raw channel writes it fails fast when the task's context is done, e.g. the scope is cancelled
+// or the consumer abandoned the channel. Sent options report liveness of the task like Heartbeat.
+type Sender[T any] struct {
+	ctx    context.Context
+	ch     chan Option[T]
+	policy OverflowPolicy
+}
+
+// Send sends value to the channel, blocked until value passed to the channel or ctx or the task's context closed.
+// If the channel is full, the policy set by WithBackpressure may drop value or fail instead of blocking.
+func (s Sender[T]) Send(ctx context.Context, value T) error {
+	return s.send(ctx, MakeValue(value))
+}
+
+// SendErr sends err to the channel like Send.
+func (s Sender[T]) SendErr(ctx context.Context, err error) error {
+	return s.send(ctx, MakeErr[T](err))
+}
+
+// Heartbeat reports liveness of the task to the watchdog, see WithWatchdog.
+func (s Sender[T]) Heartbeat() { Heartbeat(s.ctx) }
+
+// Defer registers f to be called after the task of the sender returns or panics, see Defer.
+func (s Sender[T]) Defer(f func()) { Defer(s.ctx, f) }
+
+func (s Sender[T]) send(ctx context.Context, opt Option[T]) error {
+	Heartbeat(s.ctx)
+
+	// The closed context wins over the policy, even if the channel has room.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	switch s.policy {
+	case OverflowDropNewest:
+		select {
+		case s.ch <- opt:
+		default:
+		}
+
+		return nil
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.ch <- opt:
+				return nil
+			default:
+			}
+
+			select {
+			case <-s.ch:
+			default:
+				if cap(s.ch) == 0 {
+					return nil
+				}
+			}
+		}
+
+	case OverflowError:
+		select {
+		case s.ch <- opt:
+			return nil
+		default:
+			return ErrChannelFull
+		}
+	}
+
+	select {
+	case s.ch <- opt:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// GoSender safely runs function f at a new goroutine like GoLinked, but f writes to the channel through the Sender.
+func GoSender[T any](ctx context.Context, f FuncSender[T], capacity ...int) <-chan Option[T] {
+	ch := makeChan[T](capacity...)
+
+	policy, ok := ctx.Value(contextKeyBackpressure).(OverflowPolicy)
+	if !ok {
+		policy = OverflowBlock
+	}
+
+	fn := func(ctx context.Context, _ chan<- Option[T]) error {
+		return f(ctx, Sender[T]{ctx: ctx, ch: ch, policy: policy})
+	}
+
+	goLinked(ctx, fn, ch)
+
+	return ch
+}
+
+// WithBackpressure sets the policy of the Sender of tasks started by GoSender for the case its channel is full.
+// By default the Sender blocks until the channel has room. Drop policies don't block and drop the option silently.
+func WithBackpressure(policy OverflowPolicy) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyBackpressure, policy)
+	}
+	return fn
+}
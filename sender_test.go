@@ -0,0 +1,149 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestGoSender(t *testing.T) {
+	const testN = 3
+
+	ctx := context.Background()
+
+	ch := async.GoSender(
+		ctx,
+		func(ctx context.Context, s async.Sender[int]) error {
+			for i := 0; i < testN; i++ {
+				if err := s.Send(ctx, i); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	)
+
+	values, err := async.Collect(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(values) != testN {
+		t.Error(values)
+	}
+}
+
+func TestGoSender_Cancelled(t *testing.T) {
+	sent := make(chan error, 1)
+
+	ctx, cancel := async.With(context.Background())
+
+	ch := async.GoSender(
+		ctx,
+		func(ctx context.Context, s async.Sender[int]) error {
+			err := s.Send(context.Background(), 1)
+			sent <- err
+
+			return err
+		},
+	)
+
+	cancel()
+
+	select {
+	case err := <-sent:
+		if !errors.Is(err, context.Canceled) {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Error("send is not interrupted")
+	}
+
+	for range ch {
+	}
+}
+
+func TestWithBackpressure(t *testing.T) {
+	const testN = 5
+
+	for _, tc := range []struct {
+		policy async.OverflowPolicy
+		want   []int
+		err    error
+	}{
+		{policy: async.OverflowDropNewest, want: []int{0, 1}},
+		{policy: async.OverflowDropOldest, want: []int{3, 4}},
+		{policy: async.OverflowError, want: []int{0, 1}, err: async.ErrChannelFull},
+	} {
+		ctx := async.WithBackpressure(tc.policy)(context.Background())
+
+		sent := make(chan error, 1)
+
+		ch := async.GoSender(
+			ctx,
+			func(ctx context.Context, s async.Sender[int]) error {
+				var err error
+
+				for i := 0; i < testN && err == nil; i++ {
+					err = s.Send(ctx, i)
+				}
+
+				sent <- err
+
+				return nil
+			},
+			2,
+		)
+
+		if err := <-sent; !errors.Is(err, tc.err) {
+			t.Error(tc.policy, err)
+		}
+
+		values, err := async.Collect(context.Background(), ch)
+		if err != nil {
+			t.Error(err)
+
+			continue
+		}
+
+		if len(values) != len(tc.want) || values[0] != tc.want[0] || values[1] != tc.want[1] {
+			t.Error(tc.policy, values)
+		}
+	}
+}
+
+func TestWithBackpressure_Cancelled(t *testing.T) {
+	for _, policy := range []async.OverflowPolicy{async.OverflowDropNewest, async.OverflowDropOldest, async.OverflowError} {
+		ctx, cancel := context.WithCancel(async.WithBackpressure(policy)(context.Background()))
+
+		sent := make(chan error, 1)
+
+		ch := async.GoSender(
+			ctx,
+			func(ctx context.Context, s async.Sender[int]) error {
+				<-ctx.Done()
+
+				sent <- s.Send(context.Background(), 1)
+
+				return nil
+			},
+			1,
+		)
+
+		cancel()
+
+		// The channel has room, but the sender is cancelled.
+		if err := <-sent; !errors.Is(err, context.Canceled) {
+			t.Error(policy, err)
+		}
+
+		for range ch {
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ShutdownError is returned by Scope.Shutdown when the tasks of the scope have not finished in time.
+type ShutdownError struct {
+	// Stragglers are the tasks still running after the shutdown deadline.
+	Stragglers []TaskInfo
+	Err        error
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("shutdown: %d tasks are still running: %s", len(e.Stragglers), e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error { return e.Err }
+
+// OnShutdown registers cleanup function f called by Shutdown. Cleanups are called in reverse order of registration.
+func (s *Scope) OnShutdown(f func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanups = append(s.cleanups, f)
+}
+
+// Shutdown cancels the scope, waits for its tasks until ctx is done and then calls the cleanups registered
+// by OnShutdown in reverse order, even if the tasks have not finished in time.
+// Returned error joins ShutdownError reporting the stragglers and errors of the cleanups.
+// Cleanups are called only once, subsequent calls only wait for the tasks.
+func (s *Scope) Shutdown(ctx context.Context) error {
+	s.Cancel()
+
+	var errs []error
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		s.wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		var stragglers []TaskInfo
+
+		for _, task := range s.Tasks() {
+			if task.State == TaskRunning {
+				stragglers = append(stragglers, task)
+			}
+		}
+
+		errs = append(errs, &ShutdownError{Stragglers: stragglers, Err: ctx.Err()})
+	}
+
+	s.mu.Lock()
+	cleanups := s.cleanups
+	s.cleanups = nil
+	s.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := cleanups[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
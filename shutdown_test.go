@@ -0,0 +1,85 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestScope_Shutdown(t *testing.T) {
+	scope := async.NewScope(context.Background())
+
+	var order []int
+
+	for i := 0; i < 3; i++ {
+		scope.OnShutdown(func(ctx context.Context) error {
+			order = append(order, i)
+
+			return nil
+		})
+	}
+
+	scope.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := scope.Shutdown(ctx); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(order) != 3 || order[0] != 2 || order[1] != 1 || order[2] != 0 {
+		t.Error(order)
+	}
+}
+
+func TestScope_ShutdownStragglers(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+
+	async.GoWith(
+		scope.Context(),
+		func(ch chan<- async.Option[int]) error {
+			<-release
+
+			return nil
+		},
+		async.Named("straggler"),
+	)
+
+	scope.OnShutdown(func(ctx context.Context) error { return testErr })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := scope.Shutdown(ctx)
+	if !errors.Is(err, testErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Error(err)
+
+		return
+	}
+
+	var shutdownErr *async.ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Error(err)
+
+		return
+	}
+
+	if len(shutdownErr.Stragglers) != 1 || shutdownErr.Stragglers[0].Name != "straggler" {
+		t.Error(shutdownErr.Stragglers)
+	}
+}
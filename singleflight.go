@@ -0,0 +1,72 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// SingleFlight coalesces concurrent calls with the same key into one task.
+// The zero value is ready to use.
+type SingleFlight[K comparable, T any] struct {
+	mu      sync.Mutex
+	flights map[K]*flight[T]
+}
+
+type flight[T any] struct {
+	future *Future[T]
+}
+
+// Do runs function f at a new goroutine like Go, unless the call with the same key is already running,
+// then it waits for the result of that call. The first option written by f is returned to all waiters,
+// then the call is forgotten, so the next Do call starts a new task even if f is still running.
+// Can be interrupted by closed context, this doesn't affect the running call.
+func (sf *SingleFlight[K, T]) Do(ctx context.Context, key K, f Func[T]) (T, error) {
+	sf.mu.Lock()
+
+	if sf.flights == nil {
+		sf.flights = make(map[K]*flight[T])
+	}
+
+	fl, ok := sf.flights[key]
+	if !ok {
+		fl = new(flight[T])
+
+		fn := func(ch chan<- Option[T]) error {
+			defer sf.forget(key, fl)
+
+			return f(ch)
+		}
+
+		fl.future = NewFuture(Go(ctx, fn, 1))
+		sf.flights[key] = fl
+	}
+
+	sf.mu.Unlock()
+
+	value, err := fl.future.Await(ctx)
+
+	select {
+	case <-fl.future.done:
+		sf.forget(key, fl)
+	default:
+	}
+
+	return value, err
+}
+
+// Forget makes the next Do call with the key start a new task even if the previous one is still running.
+func (sf *SingleFlight[K, T]) Forget(key K) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	delete(sf.flights, key)
+}
+
+func (sf *SingleFlight[K, T]) forget(key K, fl *flight[T]) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	if sf.flights[key] == fl {
+		delete(sf.flights, key)
+	}
+}
@@ -0,0 +1,112 @@
+package async_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestSingleFlight_Do(t *testing.T) {
+	const testCallers = 10
+
+	ctx := context.Background()
+
+	var (
+		sf    async.SingleFlight[string, int]
+		calls atomic.Int32
+		wg    sync.WaitGroup
+	)
+
+	block := make(chan struct{})
+
+	wg.Add(testCallers)
+
+	for i := 0; i < testCallers; i++ {
+		go func() {
+			defer wg.Done()
+
+			v, err := sf.Do(ctx, "key", func(ch chan<- async.Option[int]) error {
+				<-block
+				ch <- async.MakeValue(int(calls.Add(1)))
+
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+
+				return
+			}
+
+			if v != 1 {
+				t.Error(v)
+			}
+		}()
+	}
+
+	// Let callers join the flight.
+	<-time.After(50 * time.Millisecond)
+
+	close(block)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Error(calls.Load())
+	}
+}
+
+func TestSingleFlight_Panic(t *testing.T) {
+	ctx := context.Background()
+
+	var sf async.SingleFlight[string, int]
+
+	_, err := sf.Do(ctx, "key", func(ch chan<- async.Option[int]) error {
+		panic("something went wrong!")
+	})
+	if err == nil {
+		t.Fail()
+
+		return
+	}
+
+	v, err := sf.Do(ctx, "key", func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(2)
+
+		return nil
+	})
+	if err != nil || v != 2 {
+		t.Error(v, err)
+	}
+}
+
+func TestSingleFlight_Resolved(t *testing.T) {
+	ctx := context.Background()
+
+	var sf async.SingleFlight[string, int]
+
+	release := make(chan struct{})
+	defer close(release)
+
+	var calls atomic.Int32
+
+	f := func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(int(calls.Add(1)))
+
+		<-release
+
+		return nil
+	}
+
+	// The first call has returned its result, so the second one starts a new task while the first is still running.
+	for i := 1; i <= 2; i++ {
+		v, err := sf.Do(ctx, "key", f)
+		if err != nil || v != i {
+			t.Error(v, err)
+
+			return
+		}
+	}
+}
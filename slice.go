@@ -0,0 +1,121 @@
+package async
+
+import (
+	"context"
+)
+
+// MapSlice runs f over every element of in in parallel and returns the results in the order of in.
+// The number of concurrently running functions is limited by WithParallelism, otherwise all of them are started at once.
+// It stops at the first error and returns it, functions not started yet are skipped.
+// The context is modified by opt funcs like GoWith, e.g. WithParallelism or WithSemaphore.
+func MapSlice[In, Out any](ctx context.Context, in []In, f func(In) (Out, error), opt ...OptFunc) ([]Out, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ctx, _ = applyTask(ctx, opt)
+
+	g := func(i int) Func[KV[int, Out]] {
+		return func(ch chan<- Option[KV[int, Out]]) error {
+			value, err := f(in[i])
+			if err != nil {
+				return err
+			}
+
+			ch <- MakeValue(KV[int, Out]{Key: i, Value: value})
+
+			return nil
+		}
+	}
+
+	values, err := Collect(ctx, GroupN(ctx, g, len(in), limitFrom(ctx), len(in)))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Out, len(in))
+	for _, kv := range values {
+		out[kv.Key] = kv.Value
+	}
+
+	return out, nil
+}
+
+// FilterSlice runs pred over every element of in in parallel like MapSlice
+// and returns the elements satisfying pred in the order of in.
+func FilterSlice[T any](ctx context.Context, in []T, pred func(T) (bool, error), opt ...OptFunc) ([]T, error) {
+	keep, err := MapSlice(ctx, in, pred, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []T
+
+	for i, v := range in {
+		if keep[i] {
+			out = append(out, v)
+		}
+	}
+
+	return out, nil
+}
+
+// ChunkedMap splits in into chunks of chunkSize elements, runs f over the chunks in parallel like MapSlice
+// and returns the results of the chunks concatenated in the order of in. The last chunk may be shorter.
+// If chunkSize is less than 1, in is passed to f as a single chunk.
+func ChunkedMap[In, Out any](ctx context.Context, in []In, chunkSize int, f func([]In) ([]Out, error)) ([]Out, error) {
+	if chunkSize < 1 {
+		chunkSize = max(len(in), 1)
+	}
+
+	var chunks [][]In
+
+	for start := 0; start < len(in); start += chunkSize {
+		chunks = append(chunks, in[start:min(start+chunkSize, len(in))])
+	}
+
+	results, err := MapSlice(ctx, chunks, f)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Out
+
+	for _, r := range results {
+		out = append(out, r...)
+	}
+
+	return out, nil
+}
+
+// ForEach runs f over every element of items in parallel, no more than limit functions at the same time.
+// If limit is less than 1, all of them are started at once. The first error returned by f or its recovered panic
+// cancels the context passed to the other functions and is returned after all started functions have returned.
+func ForEach[T any](ctx context.Context, items []T, limit int, f func(ctx context.Context, item T) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	g := func(i int) Func[struct{}] {
+		return func(chan<- Option[struct{}]) error {
+			return f(ctx, items[i])
+		}
+	}
+
+	var first error
+
+	for opt := range GroupN(ctx, g, len(items), limit, 1) {
+		if err := opt.Err(); err != nil && first == nil {
+			first = err
+
+			cancel()
+		}
+	}
+
+	return first
+}
+
+// limitFrom returns the number of goroutines set by WithParallelism, or 0 if it's not set.
+func limitFrom(ctx context.Context) int {
+	par, _ := ctx.Value(contextKeyParallelism).(parallelism)
+
+	return par.n
+}
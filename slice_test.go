@@ -0,0 +1,156 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestMapSlice(t *testing.T) {
+	const testLimit = 2
+
+	in := []int{5, 4, 3, 2, 1}
+
+	var running, peak atomic.Int32
+
+	out, err := async.MapSlice(
+		context.Background(),
+		in,
+		func(v int) (string, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+
+			if n > peak.Load() {
+				peak.Store(n)
+			}
+
+			<-time.After(time.Duration(v) * time.Millisecond)
+
+			return strconv.Itoa(v), nil
+		},
+		async.WithParallelism(testLimit, true),
+	)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if !slices.Equal(out, []string{"5", "4", "3", "2", "1"}) {
+		t.Error(out)
+	}
+
+	if p := peak.Load(); p > testLimit {
+		t.Error(p)
+	}
+}
+
+func TestMapSlice_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	out, err := async.MapSlice(context.Background(), []int{1, 2, 3}, func(v int) (int, error) {
+		if v == 2 {
+			return 0, testErr
+		}
+
+		return v, nil
+	})
+	if !errors.Is(err, testErr) || out != nil {
+		t.Error(out, err)
+	}
+}
+
+func TestFilterSlice(t *testing.T) {
+	out, err := async.FilterSlice(context.Background(), []int{1, 2, 3, 4, 5, 6}, func(v int) (bool, error) {
+		return v%2 == 0, nil
+	})
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if !slices.Equal(out, []int{2, 4, 6}) {
+		t.Error(out)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	var sum atomic.Int32
+
+	err := async.ForEach(context.Background(), []int32{1, 2, 3, 4}, 2, func(ctx context.Context, v int32) error {
+		sum.Add(v)
+
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if sum.Load() != 10 {
+		t.Error(sum.Load())
+	}
+}
+
+func TestForEach_Panic(t *testing.T) {
+	var canceled atomic.Bool
+
+	err := async.ForEach(context.Background(), []int{0, 1}, 0, func(ctx context.Context, v int) error {
+		if v == 0 {
+			panic("something went wrong!")
+		}
+
+		<-ctx.Done()
+		canceled.Store(true)
+
+		return ctx.Err()
+	})
+
+	var panicErr *async.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Error(err)
+	}
+
+	// In-flight functions are cancelled and awaited.
+	if !canceled.Load() {
+		t.Fail()
+	}
+}
+
+func TestChunkedMap(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5, 6, 7}
+
+	var calls atomic.Int32
+
+	out, err := async.ChunkedMap(context.Background(), in, 3, func(chunk []int) ([]int, error) {
+		calls.Add(1)
+
+		res := make([]int, len(chunk))
+		for i, v := range chunk {
+			res[i] = v * 10
+		}
+
+		return res, nil
+	})
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if !slices.Equal(out, []int{10, 20, 30, 40, 50, 60, 70}) {
+		t.Error(out)
+	}
+
+	if calls.Load() != 3 {
+		t.Error(calls.Load())
+	}
+}
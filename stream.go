@@ -0,0 +1,829 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Then runs f over every value of the in channel at a new goroutine, its results fall into the returned channel.
+// Errors of the in channel are passed unchanged and stop the stage, f is not called for them.
+// Error returned by f stops the stage too. The rest of the in channel is drained, so upstream is not blocked.
+// f runs on several goroutines if the context has parallelism set by WithParallelism.
+func Then[T, U any](ctx context.Context, in <-chan Option[T], f func(T) (U, error), capacity ...int) <-chan Option[U] {
+	fn := func(ch chan<- Option[U]) error {
+		defer drain(ctx, in)
+
+		if par, ok := parallelismFrom(ctx); ok {
+			if par.ordered {
+				return thenOrdered(ctx, in, f, par.n, ch)
+			}
+
+			return thenUnordered(ctx, in, f, par.n, ch)
+		}
+
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return nil
+			}
+
+			if err := opt.Err(); err != nil {
+				return TrySendError[U](ctx, ch, err)
+			}
+
+			value, err := f(opt.Value())
+			if err != nil {
+				return TrySendError[U](ctx, ch, err)
+			}
+
+			if err := TrySend(ctx, ch, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[U](capacity...), in)
+}
+
+// MapStream runs f over every value of the in channel at a new goroutine, its results fall into the returned channel.
+// Unlike Then, errors don't stop the stage: errors of the in channel and errors returned by f are passed
+// to the returned channel element by element. The channel is closed after the in channel is closed.
+// Interrupted by closed context, then ctx error is the failure of the stage.
+func MapStream[T, U any](ctx context.Context, in <-chan Option[T], f func(T) (U, error), capacity ...int) <-chan Option[U] {
+	fn := func(ch chan<- Option[U]) error {
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return nil
+			}
+
+			out := MakeErr[U](opt.Err())
+			if opt.Err() == nil {
+				value, err := f(opt.Value())
+				out = Option[U]{value: value, err: err}
+			}
+
+			select {
+			case ch <- out:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[U](capacity...), in)
+}
+
+// Filter passes values of the in channel satisfying pred to the returned channel, errors are always passed.
+// The channel is closed after the in channel is closed. Interrupted by closed context, then ctx error is the failure of the stage.
+func Filter[T any](ctx context.Context, in <-chan Option[T], pred func(T) bool, capacity ...int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return nil
+			}
+
+			if opt.Err() == nil && !pred(opt.Value()) {
+				continue
+			}
+
+			select {
+			case ch <- opt:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[T](capacity...), in)
+}
+
+// OnError passes the in channel to the returned channel and recovers its errors by handler.
+// If handler reports true, the error is replaced with the returned fallback value, otherwise the error is passed unchanged.
+// The channel is closed after the in channel is closed. Interrupted by closed context, then ctx error is the failure of the stage.
+func OnError[T any](ctx context.Context, in <-chan Option[T], handler func(error) (T, bool), capacity ...int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return nil
+			}
+
+			if err := opt.Err(); err != nil {
+				if value, ok := handler(err); ok {
+					opt = MakeValue(value)
+				}
+			}
+
+			select {
+			case ch <- opt:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[T](capacity...), in)
+}
+
+// FanIn merges chans into one channel. The channel is closed when all of chans are closed.
+// Interrupted by closed context.
+func FanIn[T any](ctx context.Context, chans ...<-chan Option[T]) <-chan Option[T] {
+	fn := func(outCh chan<- Option[T]) error {
+		var wg sync.WaitGroup
+
+		wg.Add(len(chans))
+
+		for _, inCh := range chans {
+			go func(inCh <-chan Option[T]) {
+				defer wg.Done()
+
+				for {
+					v, ok, err := recv(ctx, inCh)
+					if err != nil || !ok {
+						return
+					}
+
+					select {
+					case outCh <- v:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}(inCh)
+		}
+
+		wg.Wait()
+
+		return nil
+	}
+
+	return goStage(ctx, fn, makeChan[T](), inputs(chans)...)
+}
+
+// MergeSorted merges chans of values sorted by less into one channel sorted the same way.
+// The next value is passed when every open channel has a value pending, so a slow producer delays the merge.
+// Errors are passed immediately and don't stop the merge. The channel is closed when all of chans are closed.
+// Interrupted by closed context.
+func MergeSorted[T any](ctx context.Context, less func(a, b T) bool, chans ...<-chan Option[T]) <-chan Option[T] {
+	fn := func(outCh chan<- Option[T]) error {
+		heads := make([]Option[T], len(chans))
+		open := make([]bool, len(chans))
+
+		// next reads the next value of the i-th channel into heads, errors are passed to the output on the way.
+		next := func(i int) error {
+			for {
+				opt, ok, err := recv(ctx, chans[i])
+				if err != nil {
+					return err
+				}
+
+				if !ok {
+					open[i] = false
+
+					return nil
+				}
+
+				if opt.Err() == nil {
+					heads[i], open[i] = opt, true
+
+					return nil
+				}
+
+				select {
+				case outCh <- opt:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+
+		for i := range chans {
+			if err := next(i); err != nil {
+				return nil
+			}
+		}
+
+		for {
+			first := -1
+
+			for i := range heads {
+				if open[i] && (first < 0 || less(heads[i].Value(), heads[first].Value())) {
+					first = i
+				}
+			}
+
+			if first < 0 {
+				return nil
+			}
+
+			if err := TrySend(ctx, outCh, heads[first].Value()); err != nil {
+				return nil
+			}
+
+			if err := next(first); err != nil {
+				return nil
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[T](), inputs(chans)...)
+}
+
+// Tee broadcasts every option of the in channel to n returned channels.
+// If capacity is defined, buffered channels will be created. Once the buffer of the slowest consumer is full,
+// Tee waits for it and the others are blocked too. Interrupted by closed context, then ctx error is passed
+// to the channels having room for it.
+func Tee[T any](ctx context.Context, in <-chan Option[T], n int, capacity ...int) []<-chan Option[T] {
+	outs := make([]chan Option[T], n)
+	res := make([]<-chan Option[T], n)
+
+	scope := scopeFrom(ctx)
+
+	for i := range outs {
+		outs[i] = makeChan[T](capacity...)
+		res[i] = outs[i]
+
+		if scope != nil {
+			scope.link(res[i], in)
+		}
+	}
+
+	_, done := track(ctx)
+
+	go func() {
+		defer done()
+
+		if scope != nil {
+			defer func() {
+				for _, out := range res {
+					scope.unlink(out)
+				}
+			}()
+		}
+
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		// cancel passes the ctx error to the channels having room for it, the rest see the closing.
+		cancel := func() {
+			for _, out := range outs {
+				select {
+				case out <- MakeErr[T](ctx.Err()):
+				default:
+				}
+			}
+		}
+
+		for {
+			v, ok, err := recv(ctx, in)
+			if err != nil {
+				cancel()
+
+				return
+			}
+
+			if !ok {
+				return
+			}
+
+			for _, out := range outs {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					cancel()
+
+					return
+				}
+			}
+		}
+	}()
+
+	return res
+}
+
+// Partition splits values of the in channel into the matched channel for values satisfying pred and the rest channel.
+// Errors are passed to both channels. If one of them is not read, the other one is blocked too.
+// The channels are closed after the in channel is closed. Interrupted by closed context.
+func Partition[T any](ctx context.Context, in <-chan Option[T], pred func(T) bool, capacity ...int) (matched, rest <-chan Option[T]) {
+	matchedCh := makeChan[T](capacity...)
+	restCh := makeChan[T](capacity...)
+
+	matched, rest = matchedCh, restCh
+
+	scope := scopeFrom(ctx)
+	if scope != nil {
+		scope.link(matched, in)
+		scope.link(rest, in)
+	}
+
+	_, done := track(ctx)
+
+	go func() {
+		defer done()
+		defer close(restCh)
+		defer close(matchedCh)
+
+		if scope != nil {
+			defer scope.unlink(rest)
+			defer scope.unlink(matched)
+		}
+
+		send := func(ch chan Option[T], opt Option[T]) bool {
+			select {
+			case ch <- opt:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil || !ok {
+				return
+			}
+
+			switch {
+			case opt.Err() != nil:
+				ok = send(matchedCh, opt) && send(restCh, opt)
+			case pred(opt.Value()):
+				ok = send(matchedCh, opt)
+			default:
+				ok = send(restCh, opt)
+			}
+
+			if !ok {
+				return
+			}
+		}
+	}()
+
+	return matched, rest
+}
+
+// Batch groups values of the in channel into slices of size values. Incomplete batch is passed to the returned channel
+// after maxWait since its first value is received. If maxWait is zero or less, batches are grouped by size only,
+// if size is less than 1, batches are grouped by time only. Errors are passed immediately after the pending batch.
+// The last incomplete batch is passed when the in channel is closed. Interrupted by closed context.
+func Batch[T any](ctx context.Context, in <-chan Option[T], size int, maxWait time.Duration, capacity ...int) <-chan Option[[]T] {
+	scope := scopeFrom(ctx)
+
+	fn := func(ch chan<- Option[[]T]) error {
+		var (
+			batch   []T
+			timer   Timer
+			timeout <-chan time.Time
+		)
+
+		flush := func() error {
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+
+			if len(batch) == 0 {
+				return nil
+			}
+
+			values := batch
+			batch = nil
+
+			return TrySend(ctx, ch, values)
+		}
+
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-timeout:
+				if err := flush(); err != nil {
+					return nil
+				}
+
+			case opt, ok := <-in:
+				if !ok {
+					opt, ok = takeFailure(scope, in)
+				}
+
+				if !ok {
+					_ = flush()
+
+					return nil
+				}
+
+				if err := opt.Err(); err != nil {
+					if flush() != nil || TrySendError[[]T](ctx, ch, err) != nil {
+						return nil
+					}
+
+					continue
+				}
+
+				batch = append(batch, opt.Value())
+
+				if len(batch) == 1 && maxWait > 0 {
+					timer = clockFrom(ctx).NewTimer(maxWait)
+					timeout = timer.C()
+				}
+
+				if size > 0 && len(batch) >= size {
+					if err := flush(); err != nil {
+						return nil
+					}
+				}
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[[]T](capacity...), in)
+}
+
+// Window groups values of the in channel into tumbling windows of d: at the end of every window the slice of values
+// received during it is passed to the returned channel, empty windows are passed as nil slices.
+// Errors are passed immediately. The last window is passed when the in channel is closed, if it's not empty.
+// Interrupted by closed context.
+func Window[T any](ctx context.Context, in <-chan Option[T], d time.Duration, capacity ...int) <-chan Option[[]T] {
+	scope := scopeFrom(ctx)
+
+	fn := func(ch chan<- Option[[]T]) error {
+		ticker := clockFrom(ctx).NewTicker(d)
+		defer ticker.Stop()
+
+		var window []T
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-ticker.C():
+				values := window
+				window = nil
+
+				if err := TrySend(ctx, ch, values); err != nil {
+					return nil
+				}
+
+			case opt, ok := <-in:
+				if !ok {
+					opt, ok = takeFailure(scope, in)
+				}
+
+				if !ok {
+					if len(window) > 0 {
+						_ = TrySend(ctx, ch, window)
+					}
+
+					return nil
+				}
+
+				if err := opt.Err(); err != nil {
+					if err := TrySendError[[]T](ctx, ch, err); err != nil {
+						return nil
+					}
+
+					continue
+				}
+
+				window = append(window, opt.Value())
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[[]T](capacity...), in)
+}
+
+// Debounce passes the last value of the in channel after no new values were received during d.
+// Errors are passed immediately. The pending value is passed when the in channel is closed.
+// Interrupted by closed context.
+func Debounce[T any](ctx context.Context, in <-chan Option[T], d time.Duration, capacity ...int) <-chan Option[T] {
+	scope := scopeFrom(ctx)
+
+	fn := func(ch chan<- Option[T]) error {
+		var (
+			pending Option[T]
+			has     bool
+			timer   Timer
+			fire    <-chan time.Time
+		)
+
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-fire:
+				fire, has = nil, false
+
+				if err := TrySend(ctx, ch, pending.Value()); err != nil {
+					return nil
+				}
+
+			case opt, ok := <-in:
+				if !ok {
+					opt, ok = takeFailure(scope, in)
+				}
+
+				if !ok {
+					if has {
+						_ = TrySend(ctx, ch, pending.Value())
+					}
+
+					return nil
+				}
+
+				if err := opt.Err(); err != nil {
+					if err := TrySendError[T](ctx, ch, err); err != nil {
+						return nil
+					}
+
+					continue
+				}
+
+				pending, has = opt, true
+
+				if timer == nil {
+					timer = clockFrom(ctx).NewTimer(d)
+				} else {
+					resetTimer(timer, fire != nil, d)
+				}
+
+				fire = timer.C()
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[T](capacity...), in)
+}
+
+// Throttle passes no more than one value of the in channel per interval.
+// The first value is passed immediately, the last of values received during the interval is passed at its end,
+// others are dropped. Errors are passed immediately. Interrupted by closed context.
+func Throttle[T any](ctx context.Context, in <-chan Option[T], interval time.Duration, capacity ...int) <-chan Option[T] {
+	scope := scopeFrom(ctx)
+
+	fn := func(ch chan<- Option[T]) error {
+		var (
+			pending Option[T]
+			has     bool
+			timer   Timer
+			fire    <-chan time.Time
+		)
+
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		start := func() {
+			if timer == nil {
+				timer = clockFrom(ctx).NewTimer(interval)
+			} else {
+				timer.Reset(interval)
+			}
+
+			fire = timer.C()
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-fire:
+				fire = nil
+
+				if !has {
+					continue
+				}
+
+				has = false
+
+				if err := TrySend(ctx, ch, pending.Value()); err != nil {
+					return nil
+				}
+
+				start()
+
+			case opt, ok := <-in:
+				if !ok {
+					opt, ok = takeFailure(scope, in)
+				}
+
+				if !ok {
+					if has {
+						_ = TrySend(ctx, ch, pending.Value())
+					}
+
+					return nil
+				}
+
+				if err := opt.Err(); err != nil {
+					if err := TrySendError[T](ctx, ch, err); err != nil {
+						return nil
+					}
+
+					continue
+				}
+
+				if fire != nil {
+					pending, has = opt, true
+
+					continue
+				}
+
+				if err := TrySend(ctx, ch, opt.Value()); err != nil {
+					return nil
+				}
+
+				start()
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[T](capacity...), in)
+}
+
+// resetTimer resets the timer t to fire after d. active reports whether t's channel is not read yet.
+func resetTimer(t Timer, active bool, d time.Duration) {
+	if active && !t.Stop() {
+		select {
+		case <-t.C():
+		default:
+		}
+	}
+
+	t.Reset(d)
+}
+
+// Wrap passes values of the plain in channel to the returned channel as options.
+// The channel is closed after the in channel is closed. Interrupted by closed context, then ctx error is the failure of the stage.
+func Wrap[T any](ctx context.Context, in <-chan T, capacity ...int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		for {
+			var (
+				v  T
+				ok bool
+			)
+
+			select {
+			case v, ok = <-in:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			if !ok {
+				return nil
+			}
+
+			select {
+			case ch <- MakeValue(v):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return Go(ctx, fn, capacity...)
+}
+
+// Unwrap splits options of the in channel into the channel of plain values and the channel of errors.
+// It stops at the first error, which is passed to the buffered errors channel, the rest of the in channel is drained.
+// Both channels are closed after the in channel is closed or the first error. Interrupted by closed context,
+// then ctx error is passed to the errors channel.
+func Unwrap[T any](ctx context.Context, in <-chan Option[T], capacity ...int) (<-chan T, <-chan error) {
+	var size int
+	if len(capacity) > 0 {
+		size = capacity[0]
+	}
+
+	values := make(chan T, size)
+	errs := make(chan error, 1)
+
+	_, done := track(ctx)
+
+	go func() {
+		defer done()
+		defer close(errs)
+		defer close(values)
+
+		for {
+			opt, ok, err := recv(ctx, in)
+			if err != nil {
+				Abandon(in)
+
+				errs <- err
+
+				return
+			}
+
+			if !ok {
+				return
+			}
+
+			if err := opt.Err(); err != nil {
+				go drain(ctx, in)
+
+				errs <- err
+
+				return
+			}
+
+			select {
+			case values <- opt.Value():
+			case <-ctx.Done():
+				Abandon(in)
+
+				errs <- ctx.Err()
+
+				return
+			}
+		}
+	}()
+
+	return values, errs
+}
+
+// Zip pairs values of the a and b channels in lockstep. The returned channel is closed when either of them is closed,
+// the rest of the other one is drained. The first error of the channels is passed and stops the stage.
+// Interrupted by closed context.
+func Zip[A, B any](ctx context.Context, a <-chan Option[A], b <-chan Option[B], capacity ...int) <-chan Option[Pair[A, B]] {
+	fn := func(ch chan<- Option[Pair[A, B]]) error {
+		defer func() {
+			go drain(ctx, a)
+			go drain(ctx, b)
+		}()
+
+		for {
+			optA, ok, err := recv(ctx, a)
+			if err != nil || !ok {
+				return nil
+			}
+
+			if err := optA.Err(); err != nil {
+				return TrySendError[Pair[A, B]](ctx, ch, err)
+			}
+
+			optB, ok, err := recv(ctx, b)
+			if err != nil || !ok {
+				return nil
+			}
+
+			if err := optB.Err(); err != nil {
+				return TrySendError[Pair[A, B]](ctx, ch, err)
+			}
+
+			if err := TrySend(ctx, ch, Pair[A, B]{First: optA.Value(), Second: optB.Value()}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return goStage(ctx, fn, makeChan[Pair[A, B]](capacity...), a, b)
+}
+
+// inputs returns chans as the inputs of the stage, see goStage.
+func inputs[T any](chans []<-chan Option[T]) []any {
+	ins := make([]any, len(chans))
+	for i, ch := range chans {
+		ins[i] = ch
+	}
+
+	return ins
+}
@@ -0,0 +1,647 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestThen(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 3; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	double := async.Then(ctx, ch, func(v int) (int, error) { return v * 2, nil })
+	format := async.Then(ctx, double, func(v int) (string, error) { return strconv.Itoa(v), nil })
+
+	var got []string
+
+	for opt := range format {
+		if err := opt.Err(); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		got = append(got, opt.Value())
+	}
+
+	if len(got) != 3 || got[0] != "2" || got[1] != "4" || got[2] != "6" {
+		t.Error(got)
+	}
+}
+
+func TestThen_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 3; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	var calls int
+
+	failed := async.Then(ctx, ch, func(v int) (int, error) { return 0, testErr })
+	next := async.Then(ctx, failed, func(v int) (int, error) {
+		calls++
+
+		return v, nil
+	})
+
+	var errs []error
+
+	for opt := range next {
+		errs = append(errs, opt.Err())
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], testErr) || calls != 0 {
+		t.Error(errs, calls)
+	}
+}
+
+func TestThen_UpstreamFailure(t *testing.T) {
+	testErr := errors.New("test error")
+
+	scope := async.NewScope(context.Background())
+	ctx := scope.Context()
+
+	src := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+
+		return testErr
+	})
+
+	double := func(v int) (int, error) { return v * 2, nil }
+
+	out := async.Filter(ctx, async.Then(ctx, async.Then(ctx, src, double), double), func(int) bool { return true })
+
+	// The failure of the source cancels the scope, but comes out of the last stage instead of the cancellation.
+	if _, err := async.Collect(ctx, out); !errors.Is(err, testErr) {
+		t.Error(err)
+
+		return
+	}
+}
+
+func TestFanIn(t *testing.T) {
+	const testN = 5
+
+	ctx := context.Background()
+
+	chans := make([]<-chan async.Option[int], testN)
+
+	for i := range chans {
+		chans[i] = async.Go(
+			ctx,
+			func(ch chan<- async.Option[int]) error {
+				ch <- async.MakeValue(1)
+				ch <- async.MakeValue(1)
+
+				return nil
+			},
+		)
+	}
+
+	var sum int
+
+	for opt := range async.FanIn(ctx, chans...) {
+		if err := opt.Err(); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		sum += opt.Value()
+	}
+
+	if sum != 2*testN {
+		t.Error(sum)
+	}
+}
+
+func TestTee(t *testing.T) {
+	const testN = 3
+	const testValues = 10
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 0; i < testValues; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	outs := async.Tee(ctx, ch, testN, testValues)
+
+	for _, out := range outs {
+		var want int
+
+		for opt := range out {
+			if opt.Err() != nil || opt.Value() != want {
+				t.Error(opt.Value(), opt.Err())
+
+				return
+			}
+
+			want++
+		}
+
+		if want != testValues {
+			t.Error(want)
+		}
+	}
+}
+
+func TestMapStream(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 4; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	out := async.MapStream(ctx, ch, func(v int) (string, error) {
+		if v == 2 {
+			return "", testErr
+		}
+
+		return strconv.Itoa(v), nil
+	})
+
+	var (
+		got  []string
+		errs []error
+	)
+
+	for opt := range out {
+		if err := opt.Err(); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		got = append(got, opt.Value())
+	}
+
+	if len(got) != 3 || got[0] != "1" || got[1] != "3" || got[2] != "4" {
+		t.Error(got)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], testErr) {
+		t.Error(errs)
+	}
+}
+
+func TestOnError(t *testing.T) {
+	transientErr := errors.New("transient error")
+	fatalErr := errors.New("fatal error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+			ch <- async.MakeErr[int](transientErr)
+			ch <- async.MakeValue(3)
+			ch <- async.MakeErr[int](fatalErr)
+
+			return nil
+		},
+	)
+
+	out := async.OnError(ctx, ch, func(err error) (int, bool) {
+		return 0, errors.Is(err, transientErr)
+	})
+
+	values, err := async.Collect(ctx, out)
+	if !errors.Is(err, fatalErr) {
+		t.Error(err)
+	}
+
+	if len(values) != 3 || values[0] != 1 || values[1] != 0 || values[2] != 3 {
+		t.Error(values)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 4; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			ch <- async.MakeErr[int](testErr)
+
+			return nil
+		},
+	)
+
+	out := async.Filter(ctx, ch, func(v int) bool { return v%2 == 0 })
+
+	var (
+		got  []int
+		errs []error
+	)
+
+	for opt := range out {
+		if err := opt.Err(); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		got = append(got, opt.Value())
+	}
+
+	if len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Error(got)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], testErr) {
+		t.Error(errs)
+	}
+}
+
+func TestFilter_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+
+	ch := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		<-release
+
+		return nil
+	})
+
+	out := async.Filter(ctx, ch, func(int) bool { return true }, 1)
+
+	cancel()
+
+	// The stage doesn't finish silently, the cancellation is its failure.
+	opts := async.Drain(out, time.Second)
+	if len(opts) != 1 || !errors.Is(opts[0].Err(), context.Canceled) {
+		t.Error(opts)
+
+		return
+	}
+}
+
+func TestBatch_Size(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 0; i < 7; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	var sizes []int
+
+	for opt := range async.Batch(ctx, ch, 3, 0) {
+		if err := opt.Err(); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		sizes = append(sizes, len(opt.Value()))
+	}
+
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Error(sizes)
+	}
+}
+
+func TestBatch_MaxWait(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+			ch <- async.MakeValue(2)
+
+			<-time.After(200 * time.Millisecond)
+
+			ch <- async.MakeValue(3)
+
+			return nil
+		},
+	)
+
+	var sizes []int
+
+	for opt := range async.Batch(ctx, ch, 100, 20*time.Millisecond) {
+		if err := opt.Err(); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		sizes = append(sizes, len(opt.Value()))
+	}
+
+	if len(sizes) != 2 || sizes[0] != 2 || sizes[1] != 1 {
+		t.Error(sizes)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 5; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			ch <- async.MakeErr[int](testErr)
+
+			<-time.After(100 * time.Millisecond)
+
+			ch <- async.MakeValue(6)
+
+			return nil
+		},
+	)
+
+	var (
+		got  []int
+		errs []error
+	)
+
+	for opt := range async.Debounce(ctx, ch, 20*time.Millisecond) {
+		if err := opt.Err(); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		got = append(got, opt.Value())
+	}
+
+	if len(got) != 2 || got[0] != 5 || got[1] != 6 {
+		t.Error(got)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], testErr) {
+		t.Error(errs)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 5; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	var got []int
+
+	for opt := range async.Throttle(ctx, ch, 50*time.Millisecond) {
+		if err := opt.Err(); err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		got = append(got, opt.Value())
+	}
+
+	if len(got) != 2 || got[0] != 1 || got[1] != 5 {
+		t.Error(got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	const testN = 3
+
+	ctx := context.Background()
+
+	in := make(chan int, testN)
+	for i := 0; i < testN; i++ {
+		in <- i
+	}
+
+	close(in)
+
+	values, err := async.Collect(ctx, async.Wrap(ctx, in))
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(values) != testN {
+		t.Error(values)
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+			ch <- async.MakeValue(2)
+			ch <- async.MakeErr[int](testErr)
+			ch <- async.MakeValue(3)
+
+			return nil
+		},
+	)
+
+	values, errs := async.Unwrap(ctx, ch)
+
+	var got []int
+	for v := range values {
+		got = append(got, v)
+	}
+
+	if len(got) != 2 {
+		t.Error(got)
+	}
+
+	if err := <-errs; !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestZip(t *testing.T) {
+	ctx := context.Background()
+
+	a := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 1; i <= 3; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			return nil
+		},
+	)
+
+	b := async.Go(
+		ctx,
+		func(ch chan<- async.Option[string]) error {
+			for _, s := range []string{"a", "b"} {
+				ch <- async.MakeValue(s)
+			}
+
+			return nil
+		},
+	)
+
+	pairs, err := async.Collect(ctx, async.Zip(ctx, a, b))
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if len(pairs) != 2 || pairs[0] != (async.Pair[int, string]{First: 1, Second: "a"}) || pairs[1].Second != "b" {
+		t.Error(pairs)
+	}
+}
+
+func TestZip_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	a := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+
+		return nil
+	})
+
+	b := async.Go(ctx, func(ch chan<- async.Option[int]) error {
+		ch <- async.MakeErr[int](testErr)
+
+		return nil
+	})
+
+	_, err := async.Collect(ctx, async.Zip(ctx, a, b))
+	if !errors.Is(err, testErr) {
+		t.Error(err)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx := context.Background()
+
+	ch := async.Go(
+		ctx,
+		func(ch chan<- async.Option[int]) error {
+			for i := 0; i < 4; i++ {
+				ch <- async.MakeValue(i)
+			}
+
+			ch <- async.MakeErr[int](testErr)
+
+			return nil
+		},
+	)
+
+	even, odd := async.Partition(ctx, ch, func(v int) bool { return v%2 == 0 }, 10)
+
+	evenValues, evenErr := async.CollectAll(ctx, even)
+	oddValues, oddErr := async.CollectAll(ctx, odd)
+
+	if !errors.Is(evenErr, testErr) || !errors.Is(oddErr, testErr) {
+		t.Error(evenErr, oddErr)
+	}
+
+	if len(evenValues) != 2 || evenValues[1] != 2 || len(oddValues) != 2 || oddValues[1] != 3 {
+		t.Error(evenValues, oddValues)
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	ctx := context.Background()
+
+	shard := func(values ...int) <-chan async.Option[int] {
+		return async.Go(ctx, func(ch chan<- async.Option[int]) error {
+			for _, v := range values {
+				ch <- async.MakeValue(v)
+			}
+
+			return nil
+		})
+	}
+
+	ch := async.MergeSorted(ctx, func(a, b int) bool { return a < b }, shard(1, 4, 7), shard(2, 5), shard(), shard(0, 3, 6, 8))
+
+	values, err := async.Collect(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if !slices.Equal(values, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Error(values)
+	}
+}
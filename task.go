@@ -0,0 +1,174 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+var (
+	contextKeyName  contextKey = "name"
+	contextKeyIndex contextKey = "index"
+)
+
+// TaskState is a state of the task tracked by a scope.
+type TaskState int
+
+const (
+	TaskRunning TaskState = iota
+	TaskDone
+)
+
+func (s TaskState) String() string {
+	switch s {
+	case TaskRunning:
+		return "running"
+	case TaskDone:
+		return "done"
+	default:
+		return "unknown"
+	}
+}
+
+// TaskInfo describes the task tracked by a scope, see Scope.Tasks.
+type TaskInfo struct {
+	Name     string
+	State    TaskState
+	Started  time.Time
+	Finished time.Time
+	// Exit is recorded in debug mode, see WithDebug.
+	Exit TaskExit
+}
+
+// Named sets the name of the task started by GoWith or Pool.SubmitWith. Nested tasks inherit the name.
+func Named(name string) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyName, name)
+	}
+	return fn
+}
+
+func nameFrom(ctx context.Context) string {
+	name, _ := ctx.Value(contextKeyName).(string)
+
+	return name
+}
+
+// TaskError is the error returned by the task's function or its recovered panic, wrapped with the task's metadata.
+type TaskError struct {
+	// Name is the name of the task set by Named.
+	Name string
+	// Index is the index of the Group function, it's -1 for tasks out of groups.
+	Index    int
+	Started  time.Time
+	Finished time.Time
+	// Panicked reports whether Err is the recovered panic.
+	Panicked bool
+	Err      error
+}
+
+func (e *TaskError) Error() string {
+	switch {
+	case e.Name != "" && e.Index >= 0:
+		return fmt.Sprintf("task %q [%d]: %s", e.Name, e.Index, e.Err)
+	case e.Name != "":
+		return fmt.Sprintf("task %q: %s", e.Name, e.Err)
+	case e.Index >= 0:
+		return fmt.Sprintf("task [%d]: %s", e.Index, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+func (e *TaskError) Unwrap() error { return e.Err }
+
+func newTaskError(ctx context.Context, started time.Time, err error, panicked bool) *TaskError {
+	return &TaskError{
+		Name:     nameFrom(ctx),
+		Index:    indexFrom(ctx),
+		Started:  started,
+		Finished: clockFrom(ctx).Now(),
+		Panicked: panicked,
+		Err:      err,
+	}
+}
+
+// withIndex sets the index of the Group function.
+func withIndex(ctx context.Context, i int) context.Context {
+	return context.WithValue(ctx, contextKeyIndex, i)
+}
+
+func indexFrom(ctx context.Context) int {
+	if i, ok := ctx.Value(contextKeyIndex).(int); ok {
+		return i
+	}
+
+	return -1
+}
+
+// Task is the handle of the task started by Start. Unlike the bare channel it keeps the task's lifecycle
+// after the channel is closed.
+type Task[T any] struct {
+	ch     <-chan Option[T]
+	done   chan struct{}
+	cancel context.CancelFunc
+	err    error
+}
+
+// Start safely runs function f at a new goroutine like GoCtx and returns the handle of the task.
+// The context of f is cancelled by Task.Cancel or after f returns.
+func Start[T any](ctx context.Context, f FuncCtx[T], capacity ...int) *Task[T] {
+	ch := makeChan[T](capacity...)
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	task := &Task[T]{
+		ch:     ch,
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	ctx, done := track(ctx)
+
+	spawn(
+		ctx,
+		func() {
+			defer done()
+
+			task.finish(run(ctx, f, ch))
+		},
+		func(err error) {
+			defer done()
+
+			task.finish(run(ctx, fail[T](err), ch))
+		},
+	)
+
+	return task
+}
+
+func (t *Task[T]) finish(err error) {
+	t.err = err
+	t.cancel()
+	close(t.done)
+}
+
+// Ch returns the channel of the task.
+func (t *Task[T]) Ch() <-chan Option[T] { return t.ch }
+
+// Done returns the channel closed after the task is finished and its channel is closed.
+func (t *Task[T]) Done() <-chan struct{} { return t.done }
+
+// Err returns the error the task has failed with: the *TaskError wrapping the error returned by f
+// or its recovered panic. It returns nil until the task is finished, see Done.
+func (t *Task[T]) Err() error {
+	select {
+	case <-t.done:
+		return t.err
+	default:
+		return nil
+	}
+}
+
+// Cancel cancels the context of the task. It doesn't wait for the task to finish.
+func (t *Task[T]) Cancel() { t.cancel() }
@@ -0,0 +1,204 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestScope_Tasks(t *testing.T) {
+	// The done tasks are kept only in debug mode.
+	scope := async.NewScope(context.Background(), async.WithDebug())
+	defer scope.Cancel()
+
+	release := make(chan struct{})
+
+	running := async.GoWith(
+		scope.Context(),
+		func(ch chan<- async.Option[int]) error {
+			<-release
+
+			return nil
+		},
+		async.Named("running"),
+	)
+
+	for range async.GoWith(scope.Context(), func(ch chan<- async.Option[int]) error { return nil }, async.Named("done")) {
+	}
+
+	// The task is marked done right after its channel is closed.
+	for i := 0; i < 100 && scope.NumGoroutine() != 1; i++ {
+		<-time.After(time.Millisecond)
+	}
+
+	tasks := scope.Tasks()
+	if len(tasks) != 2 {
+		t.Error(tasks)
+
+		return
+	}
+
+	if tasks[0].Name != "running" || tasks[0].State != async.TaskRunning {
+		t.Error(tasks[0])
+	}
+
+	if tasks[1].Name != "done" || tasks[1].State != async.TaskDone || tasks[1].Finished.IsZero() {
+		t.Error(tasks[1])
+	}
+
+	close(release)
+
+	for range running {
+	}
+}
+
+func TestScope_TasksRunning(t *testing.T) {
+	const testN = 100
+
+	scope := async.NewScope(context.Background())
+	defer scope.Cancel()
+
+	release := make(chan struct{})
+
+	running := async.GoWith(
+		scope.Context(),
+		func(ch chan<- async.Option[int]) error {
+			<-release
+
+			return nil
+		},
+		async.Named("running"),
+	)
+
+	for i := 0; i < testN; i++ {
+		for range async.Go(scope.Context(), func(ch chan<- async.Option[int]) error { return nil }) {
+		}
+	}
+
+	for i := 0; i < 100 && scope.NumGoroutine() != 1; i++ {
+		<-time.After(time.Millisecond)
+	}
+
+	if tasks := scope.Tasks(); len(tasks) != 1 || tasks[0].Name != "running" {
+		t.Error(tasks)
+	}
+
+	close(release)
+
+	for range running {
+	}
+}
+
+func TestTaskError(t *testing.T) {
+	testErr := errors.New("test error")
+
+	ctx, cancel := async.With(context.Background(), async.Named("worker"))
+	defer cancel()
+
+	ch := async.Group(
+		ctx,
+		func(i int) async.Func[int] {
+			return func(ch chan<- async.Option[int]) error {
+				if i == 2 {
+					return testErr
+				}
+
+				return nil
+			}
+		},
+		3,
+	)
+
+	for range ch {
+	}
+
+	err := async.Err(ctx)
+
+	var taskErr *async.TaskError
+	if !errors.As(err, &taskErr) || !errors.Is(err, testErr) {
+		t.Error(err)
+
+		return
+	}
+
+	if taskErr.Name != "worker" || taskErr.Index != 2 || taskErr.Panicked || taskErr.Finished.Before(taskErr.Started) {
+		t.Error(taskErr)
+	}
+}
+
+func TestStart(t *testing.T) {
+	task := async.Start(context.Background(), func(ctx context.Context, ch chan<- async.Option[int]) error {
+		ch <- async.MakeValue(1)
+
+		return nil
+	})
+
+	if v, err := async.Await(context.Background(), task.Ch()); err != nil || v != 1 {
+		t.Error(v, err)
+
+		return
+	}
+
+	<-task.Done()
+
+	if err := task.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStart_Err(t *testing.T) {
+	testErr := errors.New("test error")
+
+	task := async.Start(context.Background(), func(ctx context.Context, ch chan<- async.Option[int]) error {
+		return testErr
+	}, 1)
+
+	<-task.Done()
+
+	// The error is kept by the handle after the channel is closed.
+	for range task.Ch() {
+	}
+
+	var taskErr *async.TaskError
+
+	if err := task.Err(); !errors.Is(err, testErr) || !errors.As(err, &taskErr) {
+		t.Error(err)
+	}
+}
+
+func TestStart_Cancel(t *testing.T) {
+	started := make(chan struct{})
+
+	task := async.Start(context.Background(), func(ctx context.Context, ch chan<- async.Option[int]) error {
+		close(started)
+
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, 1)
+
+	<-started
+
+	if err := task.Err(); err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	task.Cancel()
+
+	select {
+	case <-task.Done():
+	case <-time.After(time.Second):
+		t.Error("task is not finished")
+
+		return
+	}
+
+	if err := task.Err(); !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+}
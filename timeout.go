@@ -0,0 +1,31 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrTaskTimeout = errors.New("task timeout")
+
+// Timeout wraps f to be called with the context expiring after d. If f fails after the context is expired,
+// the error is joined with ErrTaskTimeout, while cancellation of the parent context is reported as usual.
+func Timeout[T any](f FuncCtx[T], d time.Duration) FuncCtx[T] {
+	return func(ctx context.Context, ch chan<- Option[T]) error {
+		taskCtx, cancel := withTimeout(ctx, d)
+		defer cancel()
+
+		err := f(taskCtx, ch)
+		if err != nil && ctx.Err() == nil && errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
+			return errors.Join(ErrTaskTimeout, err)
+		}
+
+		return err
+	}
+}
+
+// GoTimeout runs function f at a new goroutine like GoCtx, but f is called with the context expiring after d,
+// see Timeout.
+func GoTimeout[T any](ctx context.Context, f FuncCtx[T], d time.Duration, capacity ...int) <-chan Option[T] {
+	return GoCtx(ctx, Timeout(f, d), capacity...)
+}
@@ -0,0 +1,41 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestGoTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	ch := async.GoTimeout(ctx, func(ctx context.Context, ch chan<- async.Option[int]) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, 10*time.Millisecond, 1)
+
+	_, err := async.Await(ctx, ch)
+	if !errors.Is(err, async.ErrTaskTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Error(err)
+	}
+}
+
+func TestTimeout_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	f := async.Timeout(func(ctx context.Context, ch chan<- async.Option[int]) error {
+		return ctx.Err()
+	}, time.Hour)
+
+	ch := async.GoCtx(ctx, f, 1)
+
+	_, err := async.Await(context.Background(), ch)
+	if !errors.Is(err, context.Canceled) || errors.Is(err, async.ErrTaskTimeout) {
+		t.Error(err)
+	}
+}
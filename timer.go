@@ -0,0 +1,46 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// Every calls function f on every tick of the interval at a new goroutine, all runs write to the returned channel.
+// The first call happens after the interval. Errors returned by f and recovered panics are passed to the channel
+// and don't stop the ticker. The channel is closed after ctx is done.
+func Every[T any](ctx context.Context, interval time.Duration, f Func[T], capacity ...int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		ticker := clockFrom(ctx).NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-ticker.C():
+				if err := call(ctx, f, ch); err != nil {
+					if err := TrySendError[T](ctx, ch, err); err != nil {
+						return nil
+					}
+				}
+			}
+		}
+	}
+
+	return Go(ctx, fn, capacity...)
+}
+
+// After calls function f after delay d like Go. If ctx is done before f is called, f is never called
+// and ctx error is handled the same way as an error returned by f.
+func After[T any](ctx context.Context, d time.Duration, f Func[T], capacity ...int) <-chan Option[T] {
+	fn := func(ch chan<- Option[T]) error {
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+
+		return f(ch)
+	}
+
+	return Go(ctx, fn, capacity...)
+}
@@ -0,0 +1,111 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestEvery(t *testing.T) {
+	const testRuns = 3
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+
+	ch := async.Every(
+		ctx,
+		5*time.Millisecond,
+		func(ch chan<- async.Option[int]) error {
+			calls++
+
+			if calls == 2 {
+				panic("something went wrong!")
+			}
+
+			ch <- async.MakeValue(calls)
+
+			return nil
+		},
+	)
+
+	var (
+		values int
+		errs   int
+	)
+
+	for opt := range ch {
+		if opt.Err() != nil {
+			errs++
+		} else {
+			values++
+		}
+
+		if values+errs == testRuns {
+			cancel()
+		}
+	}
+
+	if values < testRuns-1 || errs != 1 {
+		t.Error(values, errs)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	ctx := context.Background()
+
+	start := time.Now()
+
+	ch := async.After(
+		ctx,
+		20*time.Millisecond,
+		func(ch chan<- async.Option[int]) error {
+			ch <- async.MakeValue(1)
+
+			return nil
+		},
+	)
+
+	v, err := async.Await(ctx, ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v != 1 || time.Since(start) < 20*time.Millisecond {
+		t.Fail()
+	}
+}
+
+func TestAfter_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var called bool
+
+	ch := async.After(
+		ctx,
+		time.Second,
+		func(ch chan<- async.Option[int]) error {
+			called = true
+
+			return nil
+		},
+		1,
+	)
+
+	cancel()
+
+	opt := <-ch
+	if !errors.Is(opt.Err(), context.Canceled) {
+		t.Error(opt.Err())
+	}
+
+	if called {
+		t.Fail()
+	}
+}
@@ -0,0 +1,46 @@
+package async
+
+import (
+	"context"
+)
+
+var contextKeyTracer contextKey = "tracer"
+
+// Tracer starts spans of tasks. The returned context carries the span and is passed to FuncCtx functions.
+// Adapter for OpenTelemetry is provided by the asyncotel module.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span of a single task, it's ended when the task's goroutine exits.
+type Span interface {
+	// RecordError records the error returned by the task or recovered panic.
+	RecordError(err error)
+	// AddEvent records the event. Recovered panics are recorded as "panic" events.
+	AddEvent(name string)
+	End()
+}
+
+// WithTracing starts a span for every task started with the context, including Group workers.
+// Spans are children of the span carried by the task's context.
+func WithTracing(tracer Tracer) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyTracer, tracer)
+	}
+	return fn
+}
+
+type noopSpan struct{}
+
+func (noopSpan) RecordError(error) {}
+func (noopSpan) AddEvent(string)   {}
+func (noopSpan) End()              {}
+
+func startSpan(ctx context.Context) (context.Context, Span) {
+	tracer, _ := ctx.Value(contextKeyTracer).(Tracer)
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	return tracer.Start(ctx, "async.task")
+}
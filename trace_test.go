@@ -0,0 +1,73 @@
+package async_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+type testSpan struct {
+	errs   []error
+	events []string
+	ended  bool
+}
+
+type testSpanKey struct{}
+
+func (tr *testTracer) Start(ctx context.Context, name string) (context.Context, async.Span) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	s := new(testSpan)
+	tr.spans = append(tr.spans, s)
+
+	return context.WithValue(ctx, testSpanKey{}, s), s
+}
+
+func (s *testSpan) RecordError(err error) { s.errs = append(s.errs, err) }
+
+func (s *testSpan) AddEvent(name string) { s.events = append(s.events, name) }
+
+func (s *testSpan) End() { s.ended = true }
+
+func TestWithTracing_Panic(t *testing.T) {
+	tracer := new(testTracer)
+
+	ctx, cancel := async.With(context.Background(), async.WithTracing(tracer))
+	defer cancel()
+
+	var spanCtx bool
+
+	async.GoCtx(
+		ctx,
+		func(ctx context.Context, ch chan<- async.Option[int]) error {
+			_, spanCtx = ctx.Value(testSpanKey{}).(*testSpan)
+
+			panic("something went wrong!")
+		},
+	)
+
+	_ = async.WaitAll(ctx)
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+
+	if len(tracer.spans) != 1 {
+		t.Error(len(tracer.spans))
+
+		return
+	}
+
+	s := tracer.spans[0]
+
+	if !spanCtx || !s.ended || len(s.errs) != 1 || len(s.events) != 1 || s.events[0] != "panic" {
+		t.Error(spanCtx, s)
+	}
+}
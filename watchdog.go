@@ -0,0 +1,86 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var ErrTaskStuck = errors.New("task is stuck")
+
+var (
+	contextKeyWatchdog  contextKey = "watchdog"
+	contextKeyHeartbeat contextKey = "heartbeat"
+)
+
+// WatchdogAction is what the watchdog does with a stuck task.
+type WatchdogAction int
+
+const (
+	// WatchdogFlag logs the stuck task.
+	WatchdogFlag WatchdogAction = iota
+	// WatchdogCancel logs the stuck task and cancels its context, the task fails with ErrTaskStuck.
+	WatchdogCancel
+)
+
+type watchdog struct {
+	interval time.Duration
+	action   WatchdogAction
+}
+
+type heartbeat struct {
+	interval time.Duration
+	timer    Timer
+	stuck    atomic.Bool
+}
+
+// WithWatchdog makes tasks started with the context report liveness at least once per interval.
+// A task is alive while it calls Heartbeat or sends through the Sender, otherwise the watchdog takes the action.
+func WithWatchdog(interval time.Duration, action WatchdogAction) OptFunc {
+	fn := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyWatchdog, watchdog{interval: interval, action: action})
+	}
+	return fn
+}
+
+// Heartbeat reports liveness of the task owning ctx to the watchdog set by WithWatchdog.
+// It does nothing if the task is not watched.
+func Heartbeat(ctx context.Context) {
+	if hb, _ := ctx.Value(contextKeyHeartbeat).(*heartbeat); hb != nil {
+		hb.timer.Reset(hb.interval)
+	}
+}
+
+// watched wraps f to be watched by the watchdog if it's set by WithWatchdog.
+func watched[T any](ctx context.Context, f FuncCtx[T]) FuncCtx[T] {
+	wd, ok := ctx.Value(contextKeyWatchdog).(watchdog)
+	if !ok || wd.interval <= 0 {
+		return f
+	}
+
+	return func(ctx context.Context, ch chan<- Option[T]) error {
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		hb := &heartbeat{interval: wd.interval}
+
+		hb.timer = clockFrom(ctx).AfterFunc(wd.interval, func() {
+			hb.stuck.Store(true)
+
+			loggerFrom(ctx).ErrorContext(ctx, "async: task is stuck", "task", nameFrom(ctx), "interval", wd.interval.String())
+
+			if wd.action == WatchdogCancel {
+				cancel(ErrTaskStuck)
+			}
+		})
+		defer hb.timer.Stop()
+
+		err := f(context.WithValue(ctx, contextKeyHeartbeat, hb), ch)
+		if wd.action == WatchdogCancel && hb.stuck.Load() {
+			return errors.Join(ErrTaskStuck, err)
+		}
+
+		return err
+	}
+}
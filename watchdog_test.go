@@ -0,0 +1,56 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/async/v2"
+)
+
+func TestWithWatchdog_Cancel(t *testing.T) {
+	ctx := async.WithWatchdog(30*time.Millisecond, async.WatchdogCancel)(context.Background())
+
+	ch := async.GoCtx(
+		ctx,
+		func(ctx context.Context, ch chan<- async.Option[int]) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		},
+	)
+
+	_, err := async.Await(context.Background(), ch)
+	if !errors.Is(err, async.ErrTaskStuck) {
+		t.Error(err)
+	}
+}
+
+func TestWithWatchdog_Heartbeat(t *testing.T) {
+	ctx := async.WithWatchdog(50*time.Millisecond, async.WatchdogCancel)(context.Background())
+
+	ch := async.GoSender(
+		ctx,
+		func(ctx context.Context, s async.Sender[int]) error {
+			for i := 0; i < 5; i++ {
+				<-time.After(20 * time.Millisecond)
+
+				s.Heartbeat()
+			}
+
+			return s.Send(ctx, 1)
+		},
+	)
+
+	v, err := async.Await(context.Background(), ch)
+	if err != nil {
+		t.Error(err)
+
+		return
+	}
+
+	if v != 1 {
+		t.Error(v)
+	}
+}